@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/spf13/cobra"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add <dir>",
+	Short: "Create a new managed CLAUDE.md in a directory",
+	Long: `Creates a new file directly in storage and links it into the repository in one
+step, instead of writing a regular file and running save.
+
+The argument is a directory, which gets a CLAUDE.md, or a file path whose name
+matches the repository's file patterns (see 'claude-md init --pattern').
+
+The file starts empty unless content is given:
+  --from-template X  Copy the file X; if no such file exists, use the named
+                     template ~/.claude/claude-md/<user>/.templates/X
+  --from-stdin       Read the content from standard input
+
+add refuses to create a file where git tracks one, since the link would
+shadow the committed file.`,
+	Example: `  # Start a CLAUDE.md for the api package
+  claude-md add services/api
+
+  # Start from a template
+  claude-md add services/api --from-template service.md
+
+  # Pipe in generated content
+  generate-notes | claude-md add services/api --from-stdin`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdd,
+}
+
+var (
+	addFromTemplate string
+	addFromStdin    bool
+	addLinkMode     string
+	addLinkStyle    string
+)
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+	addCmd.Flags().StringVar(&addFromTemplate, "from-template", "",
+		"start from a template file or a named template in storage")
+	addCmd.Flags().BoolVar(&addFromStdin, "from-stdin", false, "read the content from standard input")
+	addCmd.Flags().StringVar(&addLinkMode, "link-mode", "",
+		"place the file as a symlink, hardlink or copy (default: the repository setting)")
+	addCmd.Flags().StringVar(&addLinkStyle, "link-style", "",
+		"create an absolute or relative symlink (default: the repository setting)")
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	if addFromTemplate != "" && addFromStdin {
+		err := errors.New("--from-template and --from-stdin cannot be used together")
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	linkMode, err := parseLinkModeFlag(addLinkMode)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	linkStyle, err := parseLinkStyleFlag(addLinkStyle)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	ctx, err := loadRepoContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+	defer syncExcludes(ctx)
+	repo, converter := ctx.Repo, ctx.Converter
+
+	path := args[0]
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "CLAUDE.md")
+	}
+	if !files.MatchesPatterns(filepath.Base(path), converter.Patterns) {
+		err := fmt.Errorf("%s does not match the managed file patterns", filepath.Base(path))
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	repoPath, err := repoRelativePath(repo.RootPath, path)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	if nested := nestedRepository(repo.RootPath, repoPath); nested != "" {
+		err := fmt.Errorf("%s is inside the repository at %s, run claude-md add there", repoPath, nested)
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	tracked, err := repo.IsTracked(repoPath)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	if tracked {
+		err := fmt.Errorf("%s is tracked by git, a link would shadow the committed file", repoPath)
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	content, err := addContent(converter.StorageRoot)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	manifest, err := converter.LoadManifest()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	storagePath, err := operations.AddFile(operations.AddOptions{
+		RepoRoot:         repo.RootPath,
+		RepoRelativePath: repoPath,
+		Content:          content,
+		PathConverter:    converter,
+		Manifest:         manifest,
+		LinkMode:         linkMode,
+		LinkStyle:        linkStyle,
+	})
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		if storagePath == "" {
+			return err
+		}
+	}
+
+	manifest.AddClone(files.CanonicalPath(repo.RootPath))
+	if err := converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	currentOutput.PrintSuccess("Added: %s -> %s", repoPath, storagePath)
+	return nil
+}
+
+// addContent returns the initial content for add from the template or stdin flags
+func addContent(storageRoot string) ([]byte, error) {
+	if addFromStdin {
+		return io.ReadAll(currentInput)
+	}
+	if addFromTemplate == "" {
+		return nil, nil
+	}
+
+	if content, err := os.ReadFile(addFromTemplate); err == nil {
+		return content, nil
+	}
+	content, err := os.ReadFile(filepath.Join(storageRoot, ".templates", addFromTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("template %s not found", addFromTemplate)
+	}
+	return content, nil
+}
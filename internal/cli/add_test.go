@@ -0,0 +1,118 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddCommand(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+
+	for _, dir := range []string{"api", "web", "tracked", "docs"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, dir), 0755))
+	}
+
+	var stdout bytes.Buffer
+
+	t.Run("FromStdin", func(t *testing.T) {
+		stdout.Reset()
+		exitCode := cli.Run([]string{"add", "api", "--from-stdin"}, cli.RunOptions{
+			Stdin:  strings.NewReader("api notes"),
+			Stdout: &stdout,
+		})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Added: api/CLAUDE.md")
+
+		linkPath := filepath.Join(repoDir, "api", "CLAUDE.md")
+		info, err := os.Lstat(linkPath)
+		require.NoError(t, err)
+		assert.NotEqual(t, 0, info.Mode()&os.ModeSymlink)
+
+		content, err := os.ReadFile(filepath.Join(storageDir, "api~CLAUDE.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "api notes", string(content))
+	})
+
+	t.Run("FromTemplate", func(t *testing.T) {
+		template := filepath.Join(t.TempDir(), "template.md")
+		require.NoError(t, os.WriteFile(template, []byte("# Template"), 0644))
+
+		stdout.Reset()
+		exitCode := cli.Run([]string{"add", "web", "--from-template", template}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		content, err := os.ReadFile(filepath.Join(repoDir, "web", "CLAUDE.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Template", string(content))
+	})
+
+	t.Run("RefusesExisting", func(t *testing.T) {
+		var stderr bytes.Buffer
+		exitCode := cli.Run([]string{"add", "api"}, cli.RunOptions{Stdout: &stdout, Stderr: &stderr})
+
+		assert.NotEqual(t, 0, exitCode)
+		assert.Contains(t, stderr.String(), "already exists")
+	})
+
+	t.Run("RefusesTrackedFile", func(t *testing.T) {
+		trackedFile := filepath.Join(repoDir, "tracked", "CLAUDE.md")
+		require.NoError(t, os.WriteFile(trackedFile, []byte("committed"), 0644))
+		cmd := exec.Command("git", "add", "tracked/CLAUDE.md")
+		cmd.Dir = repoDir
+		require.NoError(t, cmd.Run())
+		require.NoError(t, os.Remove(trackedFile))
+
+		var stderr bytes.Buffer
+		exitCode := cli.Run([]string{"add", "tracked"}, cli.RunOptions{Stdout: &stdout, Stderr: &stderr})
+
+		assert.NotEqual(t, 0, exitCode)
+		assert.Contains(t, stderr.String(), "tracked by git")
+		_, err := os.Stat(filepath.Join(storageDir, "tracked~CLAUDE.md"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("ConfiguredPatterns", func(t *testing.T) {
+		var stderr bytes.Buffer
+		exitCode := cli.Run([]string{"add", "docs/AGENTS.md"}, cli.RunOptions{Stdout: &stdout, Stderr: &stderr})
+		assert.NotEqual(t, 0, exitCode)
+		assert.Contains(t, stderr.String(), "does not match the managed file patterns")
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"init", "--pattern=CLAUDE.md", "--pattern=AGENTS.md"},
+			cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Patterns: CLAUDE.md, AGENTS.md")
+
+		stdout.Reset()
+		exitCode = cli.Run([]string{"add", "docs/AGENTS.md"}, cli.RunOptions{Stdout: &stdout})
+		require.Equal(t, 0, exitCode)
+
+		// Other commands pick up the configured patterns
+		require.NoError(t, os.Remove(filepath.Join(repoDir, "docs", "AGENTS.md")))
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Restored: docs/AGENTS.md")
+	})
+
+	_ = os.RemoveAll(storageDir)
+}
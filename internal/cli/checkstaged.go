@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var checkStagedCmd = &cobra.Command{
+	Use:   "check-staged",
+	Short: "Refuse to commit links into storage and personal CLAUDE.md files",
+	Long: `Inspects the files staged for the next commit and fails when one of them is:
+
+  - a symlink into claude-md storage, or to anywhere outside the repository,
+    which would only resolve on this machine
+  - a regular file whose content is one of your stored CLAUDE.md files
+
+Each problem is printed with the command that unstages the file. Nothing is
+printed when the staged files are fine, so it can run as a pre-commit hook;
+'claude-md hooks install' sets that up.`,
+	Args:         cobra.NoArgs,
+	RunE:         runCheckStaged,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(checkStagedCmd)
+}
+
+func runCheckStaged(cmd *cobra.Command, args []string) error {
+	// Read only and quiet, so a commit never waits for or triggers other work.
+	// A repository without storage has no stored files to compare against, but
+	// links into another repository's storage are still refused.
+	ctx, err := findRepoContext()
+	var notMapped bool
+	if err != nil {
+		if !errors.Is(err, errNotMapped) {
+			currentOutput.PrintError("Error: %v", err)
+			return err
+		}
+		notMapped = true
+	}
+	repo := ctx.Repo
+
+	entries, err := repo.StagedEntries()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	var storedFiles []files.StoredFile
+	if !notMapped {
+		ctx.readOnly = true
+		ctx.loadPatterns()
+		storedFiles, err = files.FindStoredFiles(ctx.Converter.GetRepoStorageDir(), ctx.Converter)
+		if err != nil {
+			currentOutput.PrintError("Error finding stored files: %v", err)
+			return err
+		}
+	}
+	storageRoot, err := storage.BaseDir()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	// Only staged files the size of a stored file can have its content
+	storedSizes := make(map[int64]bool, len(storedFiles))
+	for _, stored := range storedFiles {
+		if info, err := os.Stat(stored.StoragePath); err == nil {
+			storedSizes[info.Size()] = true
+		}
+	}
+
+	// Symlinks are always read, their content is the link target
+	var read, regular []string
+	for _, entry := range entries {
+		switch entry.Mode {
+		case "120000":
+			read = append(read, entry.Hash)
+		case "160000":
+			// Submodules are commits, not blobs
+		default:
+			regular = append(regular, entry.Hash)
+		}
+	}
+	sizes, err := repo.BlobSizes(regular)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	for _, hash := range regular {
+		if storedSizes[sizes[hash]] {
+			read = append(read, hash)
+		}
+	}
+	blobs, err := repo.ReadBlobs(read)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	var staged []operations.StagedFile
+	for _, entry := range entries {
+		content, ok := blobs[entry.Hash]
+		if !ok {
+			continue
+		}
+		staged = append(staged, operations.StagedFile{
+			Path:    entry.Path,
+			Symlink: entry.Mode == "120000",
+			Content: content,
+		})
+	}
+
+	problems := operations.CheckStaged(staged, operations.CheckStagedOptions{
+		RepoRoot:    repo.RootPath,
+		StorageRoot: storageRoot,
+		StoredFiles: storedFiles,
+	})
+	for _, problem := range problems {
+		currentOutput.PrintError("Staged %s: %s", problem.Path, problem.Reason)
+		currentOutput.PrintError("  fix: %s", problem.Fix)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d staged files must not be committed", len(problems))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckStagedCommand(t *testing.T) {
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+
+	setup := func(t *testing.T) (string, func(args ...string)) {
+		repoDir := cli.SetupTestGitRepo(t)
+		require.NoError(t, os.Chdir(repoDir))
+		_ = os.RemoveAll(storageDir)
+		t.Cleanup(func() { _ = os.RemoveAll(storageDir) })
+
+		git := func(args ...string) {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = repoDir
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(output))
+		}
+
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, "CLAUDE.md"), []byte("personal"), 0644))
+		require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &bytes.Buffer{}}))
+		return repoDir, git
+	}
+
+	t.Run("Clean", func(t *testing.T) {
+		repoDir, git := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main"), 0644))
+		git("add", "main.go")
+
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, 0, cli.Run([]string{"check-staged"}, cli.RunOptions{Stdout: &stdout, Stderr: &stderr}))
+		assert.Empty(t, stdout.String())
+		assert.Empty(t, stderr.String())
+	})
+
+	for _, test := range []struct {
+		name     string
+		stage    func(t *testing.T, repoDir string, git func(args ...string))
+		expected string
+	}{
+		{
+			name: "LinkIntoStorage",
+			stage: func(t *testing.T, repoDir string, git func(args ...string)) {
+				git("add", "-f", "CLAUDE.md")
+			},
+			expected: "Staged CLAUDE.md: symlink into claude-md storage",
+		},
+		{
+			name: "LinkOutsideRepository",
+			stage: func(t *testing.T, repoDir string, git func(args ...string)) {
+				require.NoError(t, os.Symlink(filepath.Join(t.TempDir(), "notes.md"), filepath.Join(repoDir, "notes.md")))
+				git("add", "notes.md")
+			},
+			expected: "Staged notes.md: symlink to ",
+		},
+		{
+			name: "PersonalContent",
+			stage: func(t *testing.T, repoDir string, git func(args ...string)) {
+				require.NoError(t, os.WriteFile(filepath.Join(repoDir, "notes.md"), []byte("personal"), 0644))
+				git("add", "notes.md")
+			},
+			expected: "Staged notes.md: content matches the stored personal CLAUDE.md",
+		},
+		{
+			name: "PersonalContentOfPattern",
+			stage: func(t *testing.T, repoDir string, git func(args ...string)) {
+				require.Equal(t, 0, cli.Run([]string{"init", "--pattern=CLAUDE.md", "--pattern=AGENTS.md"},
+					cli.RunOptions{Stdout: &bytes.Buffer{}}))
+				require.NoError(t, os.WriteFile(filepath.Join(repoDir, "AGENTS.md"), []byte("agents"), 0644))
+				require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &bytes.Buffer{}}))
+				require.NoError(t, os.WriteFile(filepath.Join(repoDir, "notes.md"), []byte("agents"), 0644))
+				git("add", "notes.md")
+			},
+			expected: "Staged notes.md: content matches the stored personal AGENTS.md",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			repoDir, git := setup(t)
+			test.stage(t, repoDir, git)
+
+			var stderr bytes.Buffer
+			assert.Equal(t, 1, cli.Run([]string{"check-staged"}, cli.RunOptions{Stdout: &bytes.Buffer{}, Stderr: &stderr}))
+			assert.Contains(t, stderr.String(), test.expected)
+			assert.Contains(t, stderr.String(), "  fix: git restore --staged -- ")
+		})
+	}
+
+	t.Run("RelativeLinkInsideRepository", func(t *testing.T) {
+		repoDir, git := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("readme"), 0644))
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "docs"), 0755))
+		require.NoError(t, os.Symlink("../README.md", filepath.Join(repoDir, "docs", "README.md")))
+		git("add", "README.md", "docs/README.md")
+
+		var stderr bytes.Buffer
+		assert.Equal(t, 0, cli.Run([]string{"check-staged"}, cli.RunOptions{Stdout: &bytes.Buffer{}, Stderr: &stderr}))
+		assert.Empty(t, stderr.String())
+	})
+}
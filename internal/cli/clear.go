@@ -1,14 +1,12 @@
 package cli
 
 import (
-	"github.com/kapetan-io/claude-md.go/internal/git"
 	"github.com/kapetan-io/claude-md.go/internal/operations"
-	"github.com/kapetan-io/claude-md.go/internal/storage"
 	"github.com/spf13/cobra"
 )
 
 var clearCmd = &cobra.Command{
-	Use:   "clear",
+	Use:   "clear [paths...]",
 	Short: "Clear CLAUDE.md symlinks from repository",
 	Long: `Removes all CLAUDE.md symbolic links from the repository.
 
@@ -16,49 +14,63 @@ This command will:
 1. Find all CLAUDE.md symlinks in the repository
 2. Remove each symlink
 
+Only symlinks into this repository's storage are removed. Links into another
+repository's or user's storage under ~/.claude/claude-md are skipped unless
+--all-namespaces is given, and links whose target no longer exists are removed
+with --dangling wherever they point.
+
+Hardlinks and copies placed by claude-md are removed too, as long as they still
+match storage; modified copies are skipped until 'claude-md sync' reconciles them.
+
 Note: This only removes the symlinks from the repository. The actual files
-remain in storage and can be restored later using 'claude-md restore'.`,
+remain in storage and can be restored later using 'claude-md restore'.
+
+Paths limit the command to the given files or directories. --include and
+--exclude take globs over repo relative paths, where ** matches any number of
+directories and a glob naming a directory selects everything below it.`,
 	Example: `  # Clear all CLAUDE.md symlinks from current repository
-  claude-md clear`,
+  claude-md clear
+
+  # Also remove links left behind by other clones' storage and broken links
+  claude-md clear --all-namespaces --dangling
+
+  # Clear only the payments service before running a vendor tool
+  claude-md clear --include='services/payments/**'`,
 	RunE: runClear,
 }
 
+var (
+	clearFilter        filterFlags
+	clearAllNamespaces bool
+	clearDangling      bool
+)
+
 func init() {
 	rootCmd.AddCommand(clearCmd)
+	addRecurseSubmodulesFlag(clearCmd)
+	addFilterFlags(clearCmd, &clearFilter)
+	clearCmd.Flags().BoolVar(&clearAllNamespaces, "all-namespaces", false,
+		"also remove symlinks into other users' or repositories' claude-md storage")
+	clearCmd.Flags().BoolVar(&clearDangling, "dangling", false,
+		"also remove symlinks whose target does not exist")
 }
 
 func runClear(cmd *cobra.Command, args []string) error {
-	repo, err := git.FindRepository()
+	ctx, err := loadRepoContext()
 	if err != nil {
-		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
+	defer ctx.Close()
+	defer syncExcludes(ctx)
+	repo, converter := ctx.Repo, ctx.Converter
 
-	email, err := repo.GetUserEmail()
+	filter, err := buildFilter(repo.RootPath, args, clearFilter)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	user, err := git.ExtractUserFromEmail(email)
-	if err != nil {
-		currentOutput.PrintError("Error: %v", err)
-		return err
-	}
-
-	originURL, err := repo.GetOriginURL()
-	if err != nil {
-		currentOutput.PrintError("Error: %v", err)
-		return err
-	}
-
-	repoName, err := git.ExtractRepoName(originURL)
-	if err != nil {
-		currentOutput.PrintError("Error: %v", err)
-		return err
-	}
-
-	converter, err := storage.NewPathConverter(user, repoName)
+	manifest, err := converter.LoadManifest()
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
@@ -67,12 +79,25 @@ func runClear(cmd *cobra.Command, args []string) error {
 	results := operations.ClearSymlinks(operations.ClearOptions{
 		RepoRoot:      repo.RootPath,
 		PathConverter: converter,
+		Manifest:      manifest,
+		Filter:        filter,
+		AllNamespaces: clearAllNamespaces,
+		Dangling:      clearDangling,
 	})
 
+	if len(results) > 0 {
+		if err := converter.SaveManifest(manifest); err != nil {
+			currentOutput.PrintError("Error: %v", err)
+			return err
+		}
+	}
+
 	var removed, skipped, errors int
+	var removedPaths []string
 	for _, result := range results {
 		if result.Success {
 			removed++
+			removedPaths = append(removedPaths, result.RepoRelativePath)
 			currentOutput.PrintSuccess("Removed: %s", result.RepoRelativePath)
 		} else if result.Skipped {
 			skipped++
@@ -83,6 +108,8 @@ func runClear(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	releaseTracked(ctx, removedPaths, true)
+
 	if removed == 0 && errors == 0 && skipped == 0 {
 		currentOutput.PrintInfo("No CLAUDE.md symlinks found in repository")
 	} else {
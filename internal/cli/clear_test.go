@@ -127,3 +127,65 @@ func TestClearCommandErrorCases(t *testing.T) {
 	require.Equal(t, 1, exitCode)
 	assert.Contains(t, stderr.String(), "Error")
 }
+
+func TestClearCommandFilters(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+
+	paths := []string{
+		"CLAUDE.md",
+		filepath.Join("services", "payments", "CLAUDE.md"),
+		filepath.Join("services", "payments", "api", "CLAUDE.md"),
+		filepath.Join("services", "orders", "CLAUDE.md"),
+	}
+	for _, path := range paths {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, filepath.Dir(path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, path), []byte(path), 0644))
+	}
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save", "--exclude=services/orders"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Summary: 3 saved")
+
+	isSymlink := func(path string) bool {
+		info, err := os.Lstat(filepath.Join(repoDir, path))
+		require.NoError(t, err)
+		return info.Mode()&os.ModeSymlink != 0
+	}
+	assert.False(t, isSymlink(paths[3]))
+
+	stdout.Reset()
+	exitCode := cli.Run([]string{"clear", "--include=services/payments/**"}, cli.RunOptions{Stdout: &stdout})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "Summary: 2 removed")
+	assert.True(t, isSymlink(paths[0]))
+	for _, path := range paths[1:3] {
+		_, err := os.Lstat(filepath.Join(repoDir, path))
+		assert.True(t, os.IsNotExist(err), path)
+	}
+
+	// Positional paths are relative to the current directory
+	require.NoError(t, os.Chdir(filepath.Join(repoDir, "services")))
+	stdout.Reset()
+	exitCode = cli.Run([]string{"restore", "payments/api"}, cli.RunOptions{Stdout: &stdout})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "Summary: 1 restored")
+	assert.True(t, isSymlink(paths[2]))
+	_, err = os.Lstat(filepath.Join(repoDir, paths[1]))
+	assert.True(t, os.IsNotExist(err))
+
+	_ = os.RemoveAll(storageDir)
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check CLAUDE.md symlinks and repair them",
+	Long: `Checks the CLAUDE.md symlinks in the repository and reports any that are
+dangling or point outside this repository's storage.
+
+With --convert-links, every symlink that leads to a stored file is rewritten to
+the given style and the style becomes the repository default. A link left
+dangling because home moved is repaired too, when its target still ends in the
+stored file's <user>/<repo>/<name> path. Links are checked after converting:
+  absolute  Links hold the absolute path of the stored file
+  relative  Links hold a path relative to their own directory, so they keep
+            working when home is mounted at a different path (for example in
+            a container)
+
+Each link is replaced atomically, so it never stops pointing at its stored file.`,
+	Example: `  # Report broken links
+  claude-md doctor
+
+  # Switch existing links to relative paths
+  claude-md doctor --convert-links=relative`,
+	RunE: runDoctor,
+}
+
+var doctorConvertLinks string
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringVar(&doctorConvertLinks, "convert-links", "",
+		"rewrite symlinks to stored files as absolute or relative")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	linkStyle, err := parseLinkStyleFlag(doctorConvertLinks)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	// Only converting links changes anything
+	load := readRepoContext
+	if linkStyle != "" {
+		load = loadRepoContext
+	}
+	ctx, err := load()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+	repo, converter := ctx.Repo, ctx.Converter
+
+	claudeFiles, err := findManagedFiles(ctx)
+	if err != nil {
+		currentOutput.PrintError("Error finding CLAUDE.md files: %v", err)
+		return err
+	}
+
+	// Converting repairs links to storage that moved, so it goes first
+	if linkStyle != "" {
+		if err := convertLinks(repo.RootPath, converter, linkStyle); err != nil {
+			return err
+		}
+	}
+
+	problems := operations.CheckLinks(claudeFiles, converter.GetRepoStorageDir())
+	for _, problem := range problems {
+		currentOutput.PrintInfo("Problem: %s -> %s (%s)", problem.RepoRelativePath, problem.Target, problem.Reason)
+	}
+
+	if len(problems) == 0 {
+		currentOutput.PrintSuccess("No problems found")
+	} else {
+		currentOutput.PrintInfo("\nSummary: %d problems", len(problems))
+	}
+
+	return nil
+}
+
+// convertLinks rewrites the repository's symlinks in linkStyle and makes it the default
+func convertLinks(repoRoot string, converter *storage.PathConverter, linkStyle storage.LinkStyle) error {
+	storedFiles, err := files.FindStoredFiles(converter.GetRepoStorageDir(), converter)
+	if err != nil {
+		currentOutput.PrintError("Error finding stored files: %v", err)
+		return err
+	}
+
+	results := operations.ConvertLinks(storedFiles, operations.ConvertOptions{
+		RepoRoot:  repoRoot,
+		LinkStyle: linkStyle,
+	})
+
+	manifest, err := converter.LoadManifest()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	manifest.LinkStyle = linkStyle
+	if err := converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	var converted, errors int
+	for _, result := range results {
+		if result.Success {
+			converted++
+			currentOutput.PrintSuccess("Converted: %s -> %s", result.RepoRelativePath, result.NewTarget)
+		} else if result.Error != nil {
+			errors++
+			currentOutput.PrintError("Error converting %s: %v", result.RepoRelativePath, result.Error)
+		} else if result.Skipped && result.SkipReason != "already converted" {
+			currentOutput.PrintInfo("Skipped %s: %s", result.RepoRelativePath, result.SkipReason)
+		}
+	}
+	currentOutput.PrintInfo("Converted %d links to %s (%d errors)", converted, linkStyle, errors)
+	return nil
+}
@@ -0,0 +1,143 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/kapetan-io/claude-md.go/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorCommandConvertLinks(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("content"), 0644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+
+	target, err := os.Readlink(claudeFile)
+	require.NoError(t, err)
+	assert.True(t, filepath.IsAbs(target))
+
+	stdout.Reset()
+	exitCode := cli.Run([]string{"doctor", "--convert-links=relative"}, cli.RunOptions{Stdout: &stdout})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "Converted: CLAUDE.md")
+	assert.Contains(t, stdout.String(), "No problems found")
+
+	target, err = os.Readlink(claudeFile)
+	require.NoError(t, err)
+	assert.False(t, filepath.IsAbs(target))
+	content, err := os.ReadFile(claudeFile)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+
+	// The relative link is recognized as already in place
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Summary: 0 restored, 1 skipped (0 warnings)")
+
+	// New links follow the repository style
+	require.NoError(t, os.Remove(claudeFile))
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+	target, err = os.Readlink(claudeFile)
+	require.NoError(t, err)
+	assert.False(t, filepath.IsAbs(target))
+
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"doctor", "--convert-links=absolute"}, cli.RunOptions{Stdout: &stdout}))
+	target, err = os.Readlink(claudeFile)
+	require.NoError(t, err)
+	assert.True(t, filepath.IsAbs(target))
+
+	// A link made when the home directory was mounted elsewhere is repaired
+	require.NoError(t, os.Remove(claudeFile))
+	require.NoError(t, os.Symlink("/old/home/.claude/claude-md/test/repo.git/CLAUDE.md", claudeFile))
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"doctor", "--convert-links=absolute"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Converted: CLAUDE.md")
+	assert.Contains(t, stdout.String(), "No problems found")
+	target, err = os.Readlink(claudeFile)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(storageDir, "CLAUDE.md"), target)
+
+	_ = os.RemoveAll(storageDir)
+}
+
+func TestDoctorCommandDanglingLink(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.Symlink(filepath.Join(repoDir, "missing"), claudeFile))
+
+	var stdout bytes.Buffer
+	exitCode := cli.Run([]string{"doctor"}, cli.RunOptions{Stdout: &stdout})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "Problem: CLAUDE.md")
+	assert.Contains(t, stdout.String(), "(dangling)")
+}
+
+func TestReadOnlyCommandsTakeNoLocks(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+	require.NoError(t, os.Chdir(repoDir))
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+	defer func() { _ = os.RemoveAll(storageDir) }()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "CLAUDE.md"), []byte("content"), 0644))
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &bytes.Buffer{}}))
+
+	// Another claude-md holds both locks, as a long watch or restore would
+	worktreeLock, err := lock.Acquire(filepath.Join(repoDir, ".git", "claude-md.lock"), time.Second)
+	require.NoError(t, err)
+	defer func() { _ = worktreeLock.Release() }()
+	storageLock, err := lock.Acquire(storageDir+".lock", time.Second)
+	require.NoError(t, err)
+	defer func() { _ = storageLock.Release() }()
+
+	for _, args := range [][]string{{"status"}, {"status", "--all-worktrees"}, {"doctor"}} {
+		var stdout bytes.Buffer
+		assert.Equal(t, 0, cli.Run(append(args, "--lock-timeout=100ms"), cli.RunOptions{Stdout: &stdout}), args)
+		assert.NotEmpty(t, stdout.String())
+	}
+
+	var stderr bytes.Buffer
+	assert.Equal(t, 1, cli.Run([]string{"save", "--lock-timeout=100ms"}, cli.RunOptions{Stdout: &bytes.Buffer{}, Stderr: &stderr}))
+	assert.Contains(t, stderr.String(), "another claude-md is running")
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/spf13/cobra"
+)
+
+var ejectCmd = &cobra.Command{
+	Use:   "eject [paths...]",
+	Short: "Turn managed CLAUDE.md files back into regular files",
+	Long: `Replaces each managed CLAUDE.md symlink with a regular file holding the stored
+content, so claude-md stops managing it. Hardlinks are replaced with independent
+files and copies are left in place. Without paths, every stored file is ejected;
+a directory ejects every managed file below it.
+
+Each file is written next to its link and renamed over it, so the path never
+ends up without either the link or the file.
+
+The stored copy stays in storage unless --purge is given, which asks for
+confirmation and then removes the stored copy and its history. A file ejected
+without --purge is left alone by status, watch and hooks, and by save and
+restore run without paths; name it in save or restore to manage it again.`,
+	Example: `  # Stop managing the CLAUDE.md in the repository root
+  claude-md eject CLAUDE.md
+
+  # Stop managing everything and delete it from storage
+  claude-md eject --purge`,
+	RunE: runEject,
+}
+
+var ejectPurge bool
+
+func init() {
+	rootCmd.AddCommand(ejectCmd)
+	ejectCmd.Flags().BoolVar(&ejectPurge, "purge", false,
+		"also remove the stored copy and its history, after confirmation")
+}
+
+func runEject(cmd *cobra.Command, args []string) error {
+	ctx, err := loadRepoContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+	defer syncExcludes(ctx)
+	repo, converter := ctx.Repo, ctx.Converter
+
+	storedFiles, err := files.FindStoredFiles(converter.GetRepoStorageDir(), converter)
+	if err != nil {
+		currentOutput.PrintError("Error finding stored files: %v", err)
+		return err
+	}
+
+	selected, err := selectStoredFiles(storedFiles, repo.RootPath, args)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	if len(selected) == 0 {
+		currentOutput.PrintInfo("No stored CLAUDE.md files found for this repository")
+		return nil
+	}
+
+	if ejectPurge {
+		for _, stored := range selected {
+			currentOutput.PrintInfo("  %s", stored.RepoRelativePath)
+		}
+		answer, err := promptChoice(fmt.Sprintf("Eject and remove %d stored files from storage?", len(selected)),
+			[]string{"yes", "no"})
+		if err != nil {
+			currentOutput.PrintError("Error: %v", err)
+			return err
+		}
+		if answer != "yes" {
+			currentOutput.PrintInfo("Aborted, nothing was changed")
+			return nil
+		}
+	}
+
+	manifest, err := converter.LoadManifest()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	results := operations.EjectFiles(selected, operations.EjectOptions{
+		RepoRoot:      repo.RootPath,
+		PathConverter: converter,
+		Manifest:      manifest,
+		Purge:         ejectPurge,
+	})
+
+	if err := converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	var ejected, skipped, errors int
+	var ejectedPaths []string
+	for _, result := range results {
+		if result.Error != nil {
+			errors++
+			currentOutput.PrintError("Error: %s: %v", result.Warning, result.Error)
+			// Ejected, but its stored copy could not be purged
+			if result.Success {
+				ejectedPaths = append(ejectedPaths, result.RepoRelativePath)
+			}
+		} else if result.Success {
+			ejected++
+			ejectedPaths = append(ejectedPaths, result.RepoRelativePath)
+			if result.Purged {
+				currentOutput.PrintSuccess("Ejected: %s (removed from storage)", result.RepoRelativePath)
+			} else {
+				currentOutput.PrintSuccess("Ejected: %s", result.RepoRelativePath)
+			}
+			if result.Warning != "" {
+				currentOutput.PrintInfo("Warning: %s", result.Warning)
+			}
+		} else if result.Skipped {
+			skipped++
+			currentOutput.PrintInfo("Warning: %s", result.Warning)
+		}
+	}
+
+	// The personal content now shows up as a change to the committed file
+	releaseTracked(ctx, ejectedPaths, false)
+
+	currentOutput.PrintInfo("\nSummary: %d ejected, %d skipped, %d errors", ejected, skipped, errors)
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEjectCommand(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	nestedFile := filepath.Join(repoDir, "docs", "CLAUDE.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(nestedFile), 0755))
+	require.NoError(t, os.WriteFile(claudeFile, []byte("root"), 0644))
+	require.NoError(t, os.WriteFile(nestedFile, []byte("docs"), 0644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+
+	t.Run("KeepsStoredCopy", func(t *testing.T) {
+		stdout.Reset()
+		exitCode := cli.Run([]string{"eject", "CLAUDE.md"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Ejected: CLAUDE.md")
+		assert.Contains(t, stdout.String(), "Summary: 1 ejected, 0 skipped, 0 errors")
+
+		info, err := os.Lstat(claudeFile)
+		require.NoError(t, err)
+		assert.True(t, info.Mode().IsRegular())
+		content, err := os.ReadFile(claudeFile)
+		require.NoError(t, err)
+		assert.Equal(t, "root", string(content))
+
+		_, err = os.Stat(filepath.Join(storageDir, "CLAUDE.md"))
+		assert.NoError(t, err)
+
+		// The other file is still managed
+		info, err = os.Lstat(nestedFile)
+		require.NoError(t, err)
+		assert.NotEqual(t, 0, info.Mode()&os.ModeSymlink)
+
+		// Nothing takes the ejected file back unless it is named
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"status"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "ejected      CLAUDE.md")
+		require.Equal(t, 0, cli.Run([]string{"save", "--update"}, cli.RunOptions{Stdout: &stdout}))
+		require.Equal(t, 0, cli.Run([]string{"restore", "--on-conflict=replace"}, cli.RunOptions{Stdout: &stdout}))
+		info, err = os.Lstat(claudeFile)
+		require.NoError(t, err)
+		assert.True(t, info.Mode().IsRegular())
+		require.Equal(t, 0, cli.Run([]string{"restore", "--on-conflict=replace", "CLAUDE.md"}, cli.RunOptions{Stdout: &stdout}))
+		info, err = os.Lstat(claudeFile)
+		require.NoError(t, err)
+		assert.NotEqual(t, 0, info.Mode()&os.ModeSymlink)
+	})
+
+	t.Run("ErrorCountedOnce", func(t *testing.T) {
+		// A directory where the replacement is written makes the eject fail
+		tmp := nestedFile + ".claude-md.tmp"
+		require.NoError(t, os.MkdirAll(filepath.Join(tmp, "blocked"), 0755))
+		defer func() { _ = os.RemoveAll(tmp) }()
+
+		stdout.Reset()
+		var stderr bytes.Buffer
+		exitCode := cli.Run([]string{"eject", "docs"}, cli.RunOptions{Stdout: &stdout, Stderr: &stderr})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stderr.String(), "Error: Skipping docs/CLAUDE.md: failed to replace link")
+		assert.Contains(t, stdout.String(), "Summary: 0 ejected, 0 skipped, 1 errors")
+	})
+
+	t.Run("PurgeDeclined", func(t *testing.T) {
+		stdout.Reset()
+		exitCode := cli.Run([]string{"eject", "--purge", "docs"}, cli.RunOptions{
+			Stdin:  strings.NewReader("no\n"),
+			Stdout: &stdout,
+		})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Aborted")
+
+		info, err := os.Lstat(nestedFile)
+		require.NoError(t, err)
+		assert.NotEqual(t, 0, info.Mode()&os.ModeSymlink)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		stdout.Reset()
+		exitCode := cli.Run([]string{"eject", "--purge", "docs"}, cli.RunOptions{
+			Stdin:  strings.NewReader("yes\n"),
+			Stdout: &stdout,
+		})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Ejected: docs/CLAUDE.md (removed from storage)")
+
+		content, err := os.ReadFile(nestedFile)
+		require.NoError(t, err)
+		assert.Equal(t, "docs", string(content))
+
+		_, err = os.Stat(filepath.Join(storageDir, "docs~CLAUDE.md"))
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(filepath.Join(storageDir, ".history", "docs~CLAUDE.md"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("NotManaged", func(t *testing.T) {
+		stdout.Reset()
+		var stderr bytes.Buffer
+		exitCode := cli.Run([]string{"eject", "missing/CLAUDE.md"}, cli.RunOptions{Stdout: &stdout, Stderr: &stderr})
+
+		assert.NotEqual(t, 0, exitCode)
+		assert.Contains(t, stderr.String(), "not managed by claude-md")
+	})
+
+	_ = os.RemoveAll(storageDir)
+}
@@ -0,0 +1,98 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitExcludes(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+	require.NoError(t, os.Chdir(repoDir))
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+	defer func() { _ = os.RemoveAll(storageDir) }()
+
+	excludePath := filepath.Join(repoDir, ".git", "info", "exclude")
+	readExclude := func(path string) string {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return ""
+		}
+		require.NoError(t, err)
+		return string(content)
+	}
+	gitStatus := func() string {
+		cmd := exec.Command("git", "status", "--porcelain", "--untracked-files=all")
+		cmd.Dir = repoDir
+		output, err := cmd.Output()
+		require.NoError(t, err)
+		return string(output)
+	}
+
+	require.NoError(t, os.WriteFile(excludePath, []byte("*.log\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "CLAUDE.md"), []byte("root"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "docs", "CLAUDE.md"), []byte("docs"), 0644))
+	assert.Contains(t, gitStatus(), "CLAUDE.md")
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+
+	assert.Equal(t, "*.log\n"+
+		"# >>> claude-md >>>\n"+
+		"# Files linked by claude-md, updated automatically\n"+
+		"/CLAUDE.md\n"+
+		"/docs/CLAUDE.md\n"+
+		"# <<< claude-md <<<\n", readExclude(excludePath))
+	assert.Empty(t, gitStatus())
+
+	t.Run("Move", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "guide"), 0755))
+		require.Equal(t, 0, cli.Run([]string{"mv", "docs/CLAUDE.md", "guide/CLAUDE.md"},
+			cli.RunOptions{Stdout: &stdout}))
+
+		assert.Contains(t, readExclude(excludePath), "/guide/CLAUDE.md\n")
+		assert.NotContains(t, readExclude(excludePath), "/docs/CLAUDE.md")
+		assert.Empty(t, gitStatus())
+	})
+
+	t.Run("PartialClear", func(t *testing.T) {
+		require.Equal(t, 0, cli.Run([]string{"clear", "guide"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, readExclude(excludePath), "/CLAUDE.md\n")
+		assert.NotContains(t, readExclude(excludePath), "/guide/CLAUDE.md")
+
+		require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, readExclude(excludePath), "/guide/CLAUDE.md\n")
+	})
+
+	t.Run("ClearRemovesBlock", func(t *testing.T) {
+		require.Equal(t, 0, cli.Run([]string{"clear"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Equal(t, "*.log\n", readExclude(excludePath))
+		require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+	})
+
+	t.Run("ConfiguredExcludeFile", func(t *testing.T) {
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"init", "--exclude-file=.gitignore"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Exclude file: .gitignore")
+
+		assert.Equal(t, "*.log\n", readExclude(excludePath))
+		gitignore := readExclude(filepath.Join(repoDir, ".gitignore"))
+		assert.Contains(t, gitignore, "/CLAUDE.md\n/guide/CLAUDE.md\n")
+		assert.Equal(t, "?? .gitignore\n", gitStatus())
+	})
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/spf13/cobra"
+)
+
+// filterFlags holds the --include and --exclude values of a command
+type filterFlags struct {
+	Include []string
+	Exclude []string
+}
+
+// addFilterFlags registers --include and --exclude on cmd
+func addFilterFlags(cmd *cobra.Command, flags *filterFlags) {
+	cmd.Flags().StringArrayVar(&flags.Include, "include", nil,
+		"only handle files matching this glob, may be repeated (** matches any directories)")
+	cmd.Flags().StringArrayVar(&flags.Exclude, "exclude", nil,
+		"skip files matching this glob, may be repeated")
+}
+
+// buildFilter turns positional path arguments and filter flags into a files.Filter
+func buildFilter(repoRoot string, args []string, flags filterFlags) (files.Filter, error) {
+	var paths []string
+	for _, arg := range args {
+		rel, err := repoRelativePath(repoRoot, arg)
+		if err != nil {
+			return files.Filter{}, err
+		}
+		paths = append(paths, rel)
+	}
+	return files.NewFilter(paths, flags.Include, flags.Exclude)
+}
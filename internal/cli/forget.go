@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/spf13/cobra"
+)
+
+var forgetCmd = &cobra.Command{
+	Use:     "forget <repo-path>...",
+	Aliases: []string{"rm"},
+	Short:   "Delete stored CLAUDE.md files and their symlinks",
+	Long: `Removes CLAUDE.md files from storage and deletes the symlinks to them.
+
+This command will:
+1. Move each stored file, with its history, into the trash at
+   ~/.claude/claude-md/<user>/<repo>/.trash/<timestamp>/
+2. Remove the symlinks to it in this clone and in every other clone of the
+   repository that claude-md has been used in
+
+A directory selects every stored file below it. Files whose working tree path
+holds a regular file are refused; use 'claude-md eject' to keep the content.
+
+To recover a forgotten file, move it from the trash back into the storage
+directory and run 'claude-md restore'.`,
+	Example: `  # Stop keeping a CLAUDE.md in storage
+  claude-md forget docs/CLAUDE.md`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runForget,
+}
+
+func init() {
+	rootCmd.AddCommand(forgetCmd)
+}
+
+func runForget(cmd *cobra.Command, args []string) error {
+	ctx, err := loadRepoContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+	defer syncExcludes(ctx)
+	repo, converter := ctx.Repo, ctx.Converter
+
+	storedFiles, err := files.FindStoredFiles(converter.GetRepoStorageDir(), converter)
+	if err != nil {
+		currentOutput.PrintError("Error finding stored files: %v", err)
+		return err
+	}
+
+	selected, err := selectStoredFiles(storedFiles, repo.RootPath, args)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	manifest, err := converter.LoadManifest()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	results := operations.ForgetFiles(selected, operations.ForgetOptions{
+		RepoRoot:      repo.RootPath,
+		PathConverter: converter,
+		Manifest:      manifest,
+	})
+
+	if err := converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	var forgotten, skipped int
+	var forgottenPaths []string
+	for _, result := range results {
+		if result.Success {
+			forgotten++
+			forgottenPaths = append(forgottenPaths, result.RepoRelativePath)
+			currentOutput.PrintSuccess("Forgot: %s (moved to %s)", result.RepoRelativePath, result.TrashPath)
+			for _, link := range result.RemovedLinks {
+				currentOutput.PrintInfo("  removed link %s", link)
+			}
+			if result.Warning != "" {
+				currentOutput.PrintInfo("Warning: %s", result.Warning)
+			}
+		} else if result.Skipped {
+			skipped++
+			currentOutput.PrintInfo("Warning: %s", result.Warning)
+		}
+	}
+
+	releaseTracked(ctx, forgottenPaths, true)
+
+	currentOutput.PrintInfo("\nSummary: %d forgotten, %d skipped", forgotten, skipped)
+
+	if skipped > 0 && forgotten == 0 {
+		return errors.New("nothing was forgotten")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForgetCommand(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+	otherDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+
+	// Save in one clone and restore in another so both hold a link
+	require.NoError(t, os.Chdir(repoDir))
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("content"), 0644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+
+	require.NoError(t, os.Chdir(otherDir))
+	require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+	otherFile := filepath.Join(otherDir, "CLAUDE.md")
+
+	t.Run("RefusesRegularFile", func(t *testing.T) {
+		require.NoError(t, os.Remove(otherFile))
+		require.NoError(t, os.WriteFile(otherFile, []byte("local"), 0644))
+
+		stdout.Reset()
+		exitCode := cli.Run([]string{"forget", "CLAUDE.md"}, cli.RunOptions{Stdout: &stdout, Stderr: &bytes.Buffer{}})
+
+		assert.NotEqual(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "not a symlink")
+		_, err := os.Stat(filepath.Join(storageDir, "CLAUDE.md"))
+		assert.NoError(t, err)
+
+		require.NoError(t, os.Remove(otherFile))
+		require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+	})
+
+	t.Run("RemovesLinksInAllClones", func(t *testing.T) {
+		stdout.Reset()
+		exitCode := cli.Run([]string{"rm", "CLAUDE.md"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Forgot: CLAUDE.md")
+		assert.Contains(t, stdout.String(), "Summary: 1 forgotten, 0 skipped")
+
+		for _, path := range []string{claudeFile, otherFile} {
+			_, err := os.Lstat(path)
+			assert.True(t, os.IsNotExist(err), path)
+		}
+
+		_, err := os.Stat(filepath.Join(storageDir, "CLAUDE.md"))
+		assert.True(t, os.IsNotExist(err))
+
+		// The stored copy can be recovered from the trash
+		trashed, err := filepath.Glob(filepath.Join(storageDir, ".trash", "*", "CLAUDE.md"))
+		require.NoError(t, err)
+		require.Len(t, trashed, 1)
+		content, err := os.ReadFile(trashed[0])
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(content))
+	})
+
+	_ = os.RemoveAll(storageDir)
+}
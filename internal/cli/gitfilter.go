@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var filterCmd = &cobra.Command{
+	Use:   "filter",
+	Short: "Keep personal instructions in committed CLAUDE.md files out of commits",
+	Long: `Configures a git clean/smudge filter for CLAUDE.md files git tracks. Personal
+instructions go at the end of the committed file, between these markers:
+
+  ` + operations.PersonalStart + `
+  ...
+  ` + operations.PersonalEnd + `
+
+On commit, git runs 'claude-md filter clean', which strips the block and keeps
+it in claude-md storage, so git only ever sees the team content. On checkout,
+git runs 'claude-md filter smudge', which adds the stored block back.
+
+Everything is configured locally, in .git/config and .git/info/attributes;
+nothing is committed. The filter is marked required, so git refuses to commit
+a filtered file when claude-md is not on PATH rather than leak the block.
+
+After you edit the block, git status lists the file as modified until the next
+git add, even though git diff shows nothing; the block is saved to storage
+whenever git stages the file.`,
+}
+
+var filterInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Configure the claude-md filter in this repository",
+	Long: `Configures the claude-md filter driver in .git/config and assigns it to the
+managed file names in .git/info/attributes. Committed files that already have a
+personal block have it saved to storage; files without one get the stored block
+added back.`,
+	Args: cobra.NoArgs,
+	RunE: runFilterInstall,
+}
+
+var filterUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the claude-md filter from this repository",
+	Long: `Strips the personal block from each committed file, keeping it in storage for
+a later install, and removes the filter from .git/config and .git/info/attributes.`,
+	Args: cobra.NoArgs,
+	RunE: runFilterUninstall,
+}
+
+var filterCleanCmd = &cobra.Command{
+	Use:   "clean <path>",
+	Short: "Strip the personal block from stdin (run by git)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFilterClean,
+}
+
+var filterSmudgeCmd = &cobra.Command{
+	Use:   "smudge <path>",
+	Short: "Add the stored personal block to stdin (run by git)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFilterSmudge,
+}
+
+func init() {
+	rootCmd.AddCommand(filterCmd)
+	filterCmd.AddCommand(filterInstallCmd, filterUninstallCmd, filterCleanCmd, filterSmudgeCmd)
+}
+
+func runFilterInstall(cmd *cobra.Command, args []string) error {
+	ctx, err := loadRepoContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	manifest, err := ctx.Converter.LoadManifest()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	prefix := "filter." + operations.FilterName + "."
+	for _, setting := range [][2]string{
+		{prefix + "clean", "claude-md filter clean %f"},
+		{prefix + "smudge", "claude-md filter smudge %f"},
+		{prefix + "required", "true"},
+	} {
+		if err := ctx.Repo.SetConfig(setting[0], setting[1]); err != nil {
+			currentOutput.PrintError("Error: %v", err)
+			return err
+		}
+	}
+
+	patterns := manifest.Patterns
+	if len(patterns) == 0 {
+		patterns = files.DefaultPatterns
+	}
+	if err := writeFilterAttributes(ctx, patterns); err != nil {
+		currentOutput.PrintError("Error: failed to update git attributes: %v", err)
+		return err
+	}
+
+	committed, err := committedFiles(ctx)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	var failed int
+	for _, file := range committed {
+		added, err := filterWorkingFile(ctx, file)
+		switch {
+		case err != nil:
+			failed++
+			currentOutput.PrintError("Error: %s: %v", file.RepoRelativePath, err)
+		case added:
+			currentOutput.PrintSuccess("Filtered: %s (personal block added)", file.RepoRelativePath)
+		default:
+			currentOutput.PrintSuccess("Filtered: %s", file.RepoRelativePath)
+		}
+	}
+
+	if len(committed) == 0 {
+		currentOutput.PrintInfo("No committed files to filter yet")
+	}
+	currentOutput.PrintInfo("\nAdd personal instructions at the end of a filtered file between\n  %s\n  %s",
+		operations.PersonalStart, operations.PersonalEnd)
+	if failed > 0 {
+		return fmt.Errorf("%d files could not be filtered", failed)
+	}
+	return nil
+}
+
+func runFilterUninstall(cmd *cobra.Command, args []string) error {
+	ctx, err := loadRepoContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	committed, err := committedFiles(ctx)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	var failed int
+	for _, file := range committed {
+		removed, err := unfilterWorkingFile(ctx, file)
+		switch {
+		case err != nil:
+			failed++
+			currentOutput.PrintError("Error: %s: %v", file.RepoRelativePath, err)
+		case removed:
+			currentOutput.PrintSuccess("Unfiltered: %s (personal block kept in storage)", file.RepoRelativePath)
+		}
+	}
+	if failed > 0 {
+		// Leave the filter in place, the remaining blocks would be committed without it
+		return fmt.Errorf("%d files could not be unfiltered", failed)
+	}
+
+	if err := writeFilterAttributes(ctx, nil); err != nil {
+		currentOutput.PrintError("Error: failed to update git attributes: %v", err)
+		return err
+	}
+	if err := ctx.Repo.RemoveConfigSection("filter." + operations.FilterName); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	currentOutput.PrintSuccess("Removed the claude-md filter")
+	return nil
+}
+
+// runFilterClean strips the personal block from the content git is about to
+// store and keeps the block in storage. Content without a block clears the
+// stored one, so a block the user deleted does not come back on checkout.
+// The team content is always written, so a storage problem never lets the
+// block reach a commit.
+func runFilterClean(cmd *cobra.Command, args []string) error {
+	content, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	clean, block, _ := operations.CleanContent(content)
+	if _, err := cmd.OutOrStdout().Write(clean); err != nil {
+		return err
+	}
+
+	ctx, err := detectRepoContext()
+	if err != nil {
+		return nil
+	}
+	storageName, err := ctx.Converter.ConvertToStorageName(filepath.FromSlash(args[0]))
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return nil
+	}
+	if _, err := ctx.Converter.SavePersonalBlock(storageName, block); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+	}
+	return nil
+}
+
+// runFilterSmudge adds the stored personal block to the content git is
+// checking out. Without a stored block the content passes through unchanged.
+func runFilterSmudge(cmd *cobra.Command, args []string) error {
+	content, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	block, err := personalBlock(args[0])
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+	}
+	_, err = cmd.OutOrStdout().Write(operations.SmudgeContent(content, block))
+	return err
+}
+
+// personalBlock returns the stored personal block of a repo relative path
+func personalBlock(path string) ([]byte, error) {
+	ctx, err := detectRepoContext()
+	if err != nil {
+		// Already reported, the content passes through
+		return nil, nil
+	}
+	storageName, err := ctx.Converter.ConvertToStorageName(filepath.FromSlash(path))
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Converter.LoadPersonalBlock(storageName)
+}
+
+// writeFilterAttributes assigns the filter to the file name patterns in
+// info/attributes, removing the assignment when there are none
+func writeFilterAttributes(ctx *repoContext, patterns []string) error {
+	attributesPath, err := ctx.Repo.InfoAttributesPath()
+	if err != nil {
+		return err
+	}
+	_, err = operations.WriteAttributesBlock(attributesPath, patterns)
+	return err
+}
+
+// committedFiles returns the managed files git tracks that are regular files
+// in the working tree
+func committedFiles(ctx *repoContext) ([]files.ClaudeFile, error) {
+	claudeFiles, err := findManagedFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var committed []files.ClaudeFile
+	for _, file := range claudeFiles {
+		if file.Tracked && !file.IsSymlink {
+			committed = append(committed, file)
+		}
+	}
+	return committed, nil
+}
+
+// filterWorkingFile brings a committed file in step with the filter: a block
+// already in the file is saved to storage, else the stored block is added.
+// Returns whether a block was added to the file.
+func filterWorkingFile(ctx *repoContext, file files.ClaudeFile) (bool, error) {
+	content, err := os.ReadFile(file.AbsolutePath)
+	if err != nil {
+		return false, err
+	}
+	storageName, err := ctx.Converter.ConvertToStorageName(file.RepoRelativePath)
+	if err != nil {
+		return false, err
+	}
+
+	if _, block, found := operations.CleanContent(content); found {
+		if _, err := ctx.Converter.SavePersonalBlock(storageName, block); err != nil {
+			return false, err
+		}
+		return false, ctx.Repo.RefreshIndexEntry(filepath.ToSlash(file.RepoRelativePath))
+	}
+
+	block, err := ctx.Converter.LoadPersonalBlock(storageName)
+	if err != nil || len(block) == 0 {
+		return false, err
+	}
+	if err := writeWorkingFile(file.AbsolutePath, operations.SmudgeContent(content, block)); err != nil {
+		return false, err
+	}
+	return true, ctx.Repo.RefreshIndexEntry(filepath.ToSlash(file.RepoRelativePath))
+}
+
+// unfilterWorkingFile saves the personal block of a committed file to storage
+// and removes it from the file. Returns whether the file had a block.
+func unfilterWorkingFile(ctx *repoContext, file files.ClaudeFile) (bool, error) {
+	content, err := os.ReadFile(file.AbsolutePath)
+	if err != nil {
+		return false, err
+	}
+	clean, block, found := operations.CleanContent(content)
+	if !found {
+		return false, nil
+	}
+
+	storageName, err := ctx.Converter.ConvertToStorageName(file.RepoRelativePath)
+	if err != nil {
+		return false, err
+	}
+	if _, err := ctx.Converter.SavePersonalBlock(storageName, block); err != nil {
+		return false, err
+	}
+	if err := writeWorkingFile(file.AbsolutePath, clean); err != nil {
+		return false, err
+	}
+	return true, ctx.Repo.RefreshIndexEntry(filepath.ToSlash(file.RepoRelativePath))
+}
+
+// writeWorkingFile replaces a working tree file, keeping its mode
+func writeWorkingFile(path string, content []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return storage.WriteAtomic(path, content, info.Mode().Perm())
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/git"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/spf13/cobra"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage git hooks that restore CLAUDE.md files",
+	Long: `Installs post-checkout, post-merge and post-rewrite hooks that run
+'claude-md restore --quiet', so links come back after switching branches,
+pulling, rebasing or cloning, and a pre-commit hook that runs
+'claude-md check-staged', so links and personal files are never committed.
+
+Existing hooks keep working: claude-md adds a marked block to shell hooks, and
+moves any other hook aside to <hook>.claude-md-chained and runs it from a
+wrapper. Hooks are written to the directory git runs them from, which honors
+core.hooksPath.
+
+With --global the hooks go into the init.templateDir of your global git config
+(set to ~/.git-template if unset), so every repository you clone or init from
+then on restores its CLAUDE.md files on checkout.`,
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the claude-md git hooks",
+	Example: `  # Restore links in this repository after checkout, merge and rebase
+  claude-md hooks install
+
+  # Restore links in every repository cloned from now on
+  claude-md hooks install --global`,
+	Args: cobra.NoArgs,
+	RunE: runHooksInstall,
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the claude-md git hooks",
+	Long: `Removes the claude-md block from each hook. Hooks that only ran claude-md are
+deleted, and hooks that were moved aside are put back.`,
+	Args: cobra.NoArgs,
+	RunE: runHooksUninstall,
+}
+
+var hooksStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which claude-md git hooks are installed",
+	Args:  cobra.NoArgs,
+	RunE:  runHooksStatus,
+}
+
+var hooksGlobal bool
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksInstallCmd, hooksUninstallCmd, hooksStatusCmd)
+	hooksCmd.PersistentFlags().BoolVar(&hooksGlobal, "global", false,
+		"use the hooks of the global init.templateDir instead of this repository")
+}
+
+// defaultTemplateDir is where install --global puts hooks when init.templateDir is unset
+const defaultTemplateDir = ".git-template"
+
+// hooksDir returns the directory to manage hooks in. With --global and create
+// set, an unset init.templateDir is pointed at ~/.git-template. An empty
+// directory means --global was given and no template directory is configured.
+func hooksDir(create bool) (string, error) {
+	if !hooksGlobal {
+		repo, err := git.FindRepository()
+		if err != nil {
+			return "", err
+		}
+		return repo.HooksDir()
+	}
+
+	templateDir, err := git.GlobalTemplateDir()
+	if err != nil {
+		return "", err
+	}
+	if templateDir == "" {
+		if !create {
+			return "", nil
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		templateDir = filepath.Join(home, defaultTemplateDir)
+		if err := git.SetGlobalTemplateDir(templateDir); err != nil {
+			return "", err
+		}
+		currentOutput.PrintInfo("Set init.templateDir to %s", templateDir)
+	}
+	return filepath.Join(templateDir, "hooks"), nil
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	dir, err := hooksDir(true)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	var failed int
+	for _, result := range operations.InstallHooks(dir) {
+		switch {
+		case result.Error != nil:
+			failed++
+			currentOutput.PrintError("Error: %s", result.Warning)
+			continue
+		case !result.Changed:
+			currentOutput.PrintInfo("Already installed: %s", result.Name)
+		case result.Chained:
+			currentOutput.PrintSuccess("Installed: %s (chained with the existing hook)", result.Name)
+		default:
+			currentOutput.PrintSuccess("Installed: %s", result.Name)
+		}
+		if result.Warning != "" {
+			currentOutput.PrintInfo("Warning: %s", result.Warning)
+		}
+	}
+
+	currentOutput.PrintInfo("\nHooks directory: %s", dir)
+	if failed > 0 {
+		return fmt.Errorf("%d hooks could not be updated", failed)
+	}
+	return nil
+}
+
+func runHooksUninstall(cmd *cobra.Command, args []string) error {
+	dir, err := hooksDir(false)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	if dir == "" {
+		currentOutput.PrintInfo("init.templateDir is not set, no global hooks to remove")
+		return nil
+	}
+
+	var failed int
+	for _, result := range operations.UninstallHooks(dir) {
+		switch {
+		case result.Error != nil:
+			failed++
+			currentOutput.PrintError("Error: %s", result.Warning)
+		case !result.Changed:
+			currentOutput.PrintInfo("Not installed: %s", result.Name)
+		case result.Chained:
+			currentOutput.PrintSuccess("Removed: %s (the existing hook was kept)", result.Name)
+		default:
+			currentOutput.PrintSuccess("Removed: %s", result.Name)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d hooks could not be updated", failed)
+	}
+	return nil
+}
+
+func runHooksStatus(cmd *cobra.Command, args []string) error {
+	dir, err := hooksDir(false)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	if dir == "" {
+		currentOutput.PrintInfo("init.templateDir is not set, no global hooks installed")
+		return nil
+	}
+
+	currentOutput.PrintInfo("Hooks directory: %s", dir)
+	for _, result := range operations.HookStatus(dir) {
+		if result.Error != nil {
+			currentOutput.PrintError("Error: %s", result.Warning)
+			continue
+		}
+		state := string(result.State)
+		if result.Chained {
+			state += " (chained with an existing hook)"
+		}
+		currentOutput.PrintInfo("%-14s %s", result.Name, state)
+		if result.Warning != "" {
+			currentOutput.PrintInfo("Warning: %s", result.Warning)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooksCommand(t *testing.T) {
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	hookNames := []string{"post-checkout", "post-merge", "post-rewrite"}
+
+	t.Run("InstallAndUninstall", func(t *testing.T) {
+		repoDir := cli.SetupTestGitRepo(t)
+		require.NoError(t, os.Chdir(repoDir))
+		hooksDir := filepath.Join(repoDir, ".git", "hooks")
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"hooks", "install"}, cli.RunOptions{Stdout: &stdout}))
+		for _, name := range hookNames {
+			assert.Contains(t, stdout.String(), "Installed: "+name)
+
+			info, err := os.Stat(filepath.Join(hooksDir, name))
+			require.NoError(t, err)
+			assert.NotZero(t, info.Mode()&0100, "hook is executable")
+			content, err := os.ReadFile(filepath.Join(hooksDir, name))
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "claude-md restore --quiet")
+		}
+		assert.Contains(t, stdout.String(), "Installed: pre-commit")
+		content, err := os.ReadFile(filepath.Join(hooksDir, "pre-commit"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "claude-md check-staged </dev/null || exit 1")
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"hooks", "install"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Already installed: post-checkout")
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"hooks", "status"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "post-merge     installed\n")
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"hooks", "uninstall"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Removed: post-rewrite")
+		for _, name := range hookNames {
+			_, err := os.Stat(filepath.Join(hooksDir, name))
+			assert.True(t, os.IsNotExist(err))
+		}
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"hooks", "status"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "post-merge     not installed\n")
+	})
+
+	t.Run("ChainsShellHook", func(t *testing.T) {
+		repoDir := cli.SetupTestGitRepo(t)
+		require.NoError(t, os.Chdir(repoDir))
+
+		hookPath := filepath.Join(repoDir, ".git", "hooks", "post-merge")
+		original := "#!/bin/sh\necho \"$1\" > \"$(dirname \"$0\")/ran\"\nexit 3\n"
+		require.NoError(t, os.WriteFile(hookPath, []byte(original), 0755))
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"hooks", "install"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Installed: post-merge (chained with the existing hook)")
+
+		// The existing hook still runs, with its arguments and exit status
+		cmd := exec.Command(hookPath, "squash")
+		cmd.Env = append(os.Environ(), "PATH=/usr/bin:/bin")
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.ExitCode())
+		ran, err := os.ReadFile(filepath.Join(repoDir, ".git", "hooks", "ran"))
+		require.NoError(t, err)
+		assert.Equal(t, "squash\n", string(ran))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"hooks", "uninstall"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Removed: post-merge (the existing hook was kept)")
+		content, err := os.ReadFile(hookPath)
+		require.NoError(t, err)
+		assert.Equal(t, original, string(content))
+	})
+
+	t.Run("ChainsOtherHook", func(t *testing.T) {
+		repoDir := cli.SetupTestGitRepo(t)
+		require.NoError(t, os.Chdir(repoDir))
+
+		hookPath := filepath.Join(repoDir, ".git", "hooks", "post-checkout")
+		original := "#!/usr/bin/env python3\nprint('checked out')\n"
+		require.NoError(t, os.WriteFile(hookPath, []byte(original), 0755))
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"hooks", "install"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Installed: post-checkout (chained with the existing hook)")
+
+		chained, err := os.ReadFile(hookPath + ".claude-md-chained")
+		require.NoError(t, err)
+		assert.Equal(t, original, string(chained))
+		wrapper, err := os.ReadFile(hookPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(wrapper), `exec "$0.claude-md-chained" "$@"`)
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"hooks", "status"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "post-checkout  installed (chained with an existing hook)")
+
+		require.Equal(t, 0, cli.Run([]string{"hooks", "uninstall"}, cli.RunOptions{Stdout: &stdout}))
+		content, err := os.ReadFile(hookPath)
+		require.NoError(t, err)
+		assert.Equal(t, original, string(content))
+		_, err = os.Stat(hookPath + ".claude-md-chained")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("KeepsChainedHook", func(t *testing.T) {
+		repoDir := cli.SetupTestGitRepo(t)
+		require.NoError(t, os.Chdir(repoDir))
+
+		// A hook moved aside before, and a new non-shell hook put in its place
+		hookPath := filepath.Join(repoDir, ".git", "hooks", "post-checkout")
+		chained := "#!/usr/bin/env python3\nprint('first')\n"
+		current := "#!/usr/bin/env python3\nprint('second')\n"
+		require.NoError(t, os.WriteFile(hookPath+".claude-md-chained", []byte(chained), 0755))
+		require.NoError(t, os.WriteFile(hookPath, []byte(current), 0755))
+
+		var stderr bytes.Buffer
+		require.Equal(t, 1, cli.Run([]string{"hooks", "install"}, cli.RunOptions{Stdout: &bytes.Buffer{}, Stderr: &stderr}))
+		assert.Contains(t, stderr.String(), "post-checkout.claude-md-chained already exists, merge the two hooks by hand")
+
+		content, err := os.ReadFile(hookPath + ".claude-md-chained")
+		require.NoError(t, err)
+		assert.Equal(t, chained, string(content))
+		content, err = os.ReadFile(hookPath)
+		require.NoError(t, err)
+		assert.Equal(t, current, string(content))
+	})
+
+	t.Run("RespectsCoreHooksPath", func(t *testing.T) {
+		repoDir := cli.SetupTestGitRepo(t)
+		require.NoError(t, os.Chdir(repoDir))
+
+		cmd := exec.Command("git", "config", "core.hooksPath", "custom-hooks")
+		cmd.Dir = repoDir
+		require.NoError(t, cmd.Run())
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"hooks", "install"}, cli.RunOptions{Stdout: &stdout}))
+		_, err := os.Stat(filepath.Join(repoDir, "custom-hooks", "post-checkout"))
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(repoDir, ".git", "hooks", "post-checkout"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Global", func(t *testing.T) {
+		home := t.TempDir()
+		require.NoError(t, os.Chdir(home))
+		t.Setenv("HOME", home)
+		t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(home, ".gitconfig"))
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"hooks", "status", "--global"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "init.templateDir is not set")
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"hooks", "install", "--global"}, cli.RunOptions{Stdout: &stdout}))
+		templateDir := filepath.Join(home, ".git-template")
+		assert.Contains(t, stdout.String(), "Set init.templateDir to "+templateDir)
+
+		output, err := exec.Command("git", "config", "--global", "init.templateDir").Output()
+		require.NoError(t, err)
+		assert.Equal(t, templateDir+"\n", string(output))
+		_, err = os.Stat(filepath.Join(templateDir, "hooks", "post-rewrite"))
+		assert.NoError(t, err)
+
+		// New repositories get the hooks from the template
+		repoDir := filepath.Join(t.TempDir(), "fresh")
+		require.NoError(t, exec.Command("git", "init", repoDir).Run())
+		content, err := os.ReadFile(filepath.Join(repoDir, ".git", "hooks", "post-checkout"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "claude-md restore --quiet")
+	})
+}
@@ -2,8 +2,10 @@ package cli
 
 import (
 	"os"
+	"strings"
 
-	"github.com/kapetan-io/claude-md.go/internal/git"
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
 	"github.com/kapetan-io/claude-md.go/internal/storage"
 	"github.com/spf13/cobra"
 )
@@ -17,59 +19,118 @@ The storage location is determined by:
 - User: extracted from git config user.email (part before @)
 - Repository: extracted from git remote origin URL
 
-This command must be run from within a git repository with an origin remote configured.`,
+This command must be run from within a git repository with an origin remote configured.
+
+Settings for the repository can be given on the first run or changed by running init again:
+  --link-mode  How stored files are placed in the working tree: symlink (default),
+               hardlink or copy. Use copy where symlinks into $HOME are not followed,
+               such as devcontainers and sandboxed tools; 'claude-md sync' then keeps
+               copies and storage in step.
+  --link-style How symlinks refer to storage: absolute (default) or relative. Use
+               relative when home is mounted at a different path, for example in
+               a container; 'claude-md doctor --convert-links' rewrites existing links.
+  --pattern    File names to manage, as globs matched case-insensitively against
+               the file name (default CLAUDE.md). Repeat the flag for several,
+               for example --pattern=CLAUDE.md --pattern=AGENTS.md.
+  --exclude-file
+               Where to list linked files so git status does not offer them for
+               commit, relative to the repository root (default .git/info/exclude).
+               Use .gitignore to share the list with everyone.
+  --tracked    What to do with files git tracks, which a link would replace with a
+               symlink into $HOME in the next commit:
+                 refuse         Leave them alone (default)
+                 skip-worktree  Link them and mark them skip-worktree so git
+                                ignores the link; clear puts the committed file back
+                 local          Leave them alone and manage a personal
+                                CLAUDE.local.md next to each instead`,
 	Example: `  # Initialize storage for current repository
-  claude-md init`,
+  claude-md init
+
+  # Materialize copies instead of symlinks for this repository
+  claude-md init --link-mode=copy
+
+  # Create relative symlinks so links survive home being mounted elsewhere
+  claude-md init --link-style=relative
+
+  # Keep personal instructions in CLAUDE.local.md where CLAUDE.md is committed
+  claude-md init --tracked=local`,
 	RunE: runInit,
 }
 
+var (
+	initLinkMode  string
+	initLinkStyle string
+	initPatterns  []string
+	initExclude   string
+	initTracked   string
+)
+
+// initSettings are the repository settings given to init, zero values are left unchanged
+type initSettings struct {
+	LinkMode    storage.LinkMode
+	LinkStyle   storage.LinkStyle
+	Patterns    []string
+	ExcludeFile string
+	Tracked     storage.TrackedPolicy
+}
+
 func init() {
 	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVar(&initLinkMode, "link-mode", "",
+		"default link mode for this repository: symlink, hardlink or copy")
+	initCmd.Flags().StringVar(&initLinkStyle, "link-style", "",
+		"how symlinks refer to storage for this repository: absolute or relative")
+	initCmd.Flags().StringSliceVar(&initPatterns, "pattern", nil,
+		"file name glob to manage, may be repeated (default: CLAUDE.md)")
+	initCmd.Flags().StringVar(&initExclude, "exclude-file", "",
+		"file listing linked files for git to ignore (default: .git/info/exclude)")
+	initCmd.Flags().StringVar(&initTracked, "tracked", "",
+		"what to do with files git tracks: refuse, skip-worktree or local")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
-	repo, err := git.FindRepository()
+	linkMode, err := parseLinkModeFlag(initLinkMode)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	email, err := repo.GetUserEmail()
+	linkStyle, err := parseLinkStyleFlag(initLinkStyle)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	user, err := git.ExtractUserFromEmail(email)
-	if err != nil {
-		currentOutput.PrintError("Error: %v", err)
-		return err
+	var tracked storage.TrackedPolicy
+	if initTracked != "" {
+		if tracked, err = storage.ParseTrackedPolicy(initTracked); err != nil {
+			currentOutput.PrintError("Error: %v", err)
+			return err
+		}
 	}
 
-	originURL, err := repo.GetOriginURL()
-	if err != nil {
-		currentOutput.PrintError("Error: %v", err)
-		return err
+	for _, pattern := range initPatterns {
+		if err := files.ValidatePattern(pattern); err != nil {
+			currentOutput.PrintError("Error: %v", err)
+			return err
+		}
 	}
+	settings := initSettings{LinkMode: linkMode, LinkStyle: linkStyle, Patterns: initPatterns,
+		ExcludeFile: initExclude, Tracked: tracked}
 
-	repoName, err := git.ExtractRepoName(originURL)
+	ctx, err := loadRepoContext()
 	if err != nil {
-		currentOutput.PrintError("Error: %v", err)
-		return err
-	}
-
-	converter, err := storage.NewPathConverter(user, repoName)
-	if err != nil {
-		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
+	defer ctx.Close()
+	converter := ctx.Converter
 
 	storageDir := converter.GetRepoStorageDir()
 
 	if info, err := os.Stat(storageDir); err == nil && info.IsDir() {
 		currentOutput.PrintInfo("Storage directory already exists: %s", storageDir)
-		currentOutput.PrintInfo("User: %s", user)
-		return nil
+		currentOutput.PrintInfo("User: %s", ctx.User)
+		return applyInitSettings(ctx, settings)
 	}
 
 	if err := converter.EnsureStorageDir(); err != nil {
@@ -78,7 +139,81 @@ func runInit(cmd *cobra.Command, args []string) error {
 	}
 
 	currentOutput.PrintSuccess("Created storage directory: %s", storageDir)
-	currentOutput.PrintInfo("User: %s", user)
+	currentOutput.PrintInfo("User: %s", ctx.User)
+
+	return applyInitSettings(ctx, settings)
+}
+
+// applyInitSettings records the repository settings given to init
+func applyInitSettings(ctx *repoContext, settings initSettings) error {
+	if settings.LinkMode == "" && settings.LinkStyle == "" && len(settings.Patterns) == 0 &&
+		settings.ExcludeFile == "" && settings.Tracked == "" {
+		return nil
+	}
+	converter := ctx.Converter
 
+	manifest, err := converter.LoadManifest()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	if settings.LinkMode != "" {
+		manifest.LinkMode = settings.LinkMode
+	}
+	if settings.LinkStyle != "" {
+		manifest.LinkStyle = settings.LinkStyle
+	}
+	if len(settings.Patterns) > 0 {
+		manifest.Patterns = settings.Patterns
+	}
+	if settings.Tracked != "" {
+		manifest.Tracked = settings.Tracked
+	}
+	if settings.ExcludeFile != "" && settings.ExcludeFile != manifest.ExcludeFile {
+		// Move the list of linked files out of the old exclude file
+		if previous, err := excludeFilePath(ctx.Repo, manifest); err == nil {
+			if _, err := operations.WriteExcludeBlock(previous, nil); err != nil {
+				currentOutput.PrintError("Error: failed to update %s: %v", previous, err)
+			}
+		}
+		manifest.ExcludeFile = settings.ExcludeFile
+	}
+	if err := converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	syncExcludes(ctx)
+
+	if settings.LinkMode != "" {
+		currentOutput.PrintInfo("Link mode: %s", settings.LinkMode)
+	}
+	if settings.LinkStyle != "" {
+		currentOutput.PrintInfo("Link style: %s", settings.LinkStyle)
+	}
+	if len(settings.Patterns) > 0 {
+		currentOutput.PrintInfo("Patterns: %s", strings.Join(settings.Patterns, ", "))
+	}
+	if settings.ExcludeFile != "" {
+		currentOutput.PrintInfo("Exclude file: %s", settings.ExcludeFile)
+	}
+	if settings.Tracked != "" {
+		currentOutput.PrintInfo("Tracked files: %s", settings.Tracked)
+	}
 	return nil
 }
+
+// parseLinkModeFlag validates a --link-mode value, returning "" when the flag was not given
+func parseLinkModeFlag(s string) (storage.LinkMode, error) {
+	if s == "" {
+		return "", nil
+	}
+	return storage.ParseLinkMode(s)
+}
+
+// parseLinkStyleFlag validates a --link-style value, returning "" when the flag was not given
+func parseLinkStyleFlag(s string) (storage.LinkStyle, error) {
+	if s == "" {
+		return "", nil
+	}
+	return storage.ParseLinkStyle(s)
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/spf13/cobra"
+)
+
+var mvCmd = &cobra.Command{
+	Use:   "mv <old> <new>",
+	Short: "Relocate a managed CLAUDE.md within the repository",
+	Long: `Moves a managed CLAUDE.md to a new path in the repository.
+
+This command will:
+1. Rename the stored file, its history and its manifest entry so restore
+   places it at the new path in every clone
+2. Move the link from the old path to the new one, creating missing parent
+   directories in this clone
+3. Remove the stale link at the old path in other clones, or move it when
+   the new directory already exists there
+
+If the link already moved along with its directory, it is re-pointed in place.
+'claude-md status' reports links that moved this way.`,
+	Example: `  # After renaming pkg/api to pkg/server
+  claude-md mv pkg/api/CLAUDE.md pkg/server/CLAUDE.md`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMv,
+}
+
+func init() {
+	rootCmd.AddCommand(mvCmd)
+}
+
+func runMv(cmd *cobra.Command, args []string) error {
+	ctx, err := loadRepoContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+	defer syncExcludes(ctx)
+	repo, converter := ctx.Repo, ctx.Converter
+
+	oldPath, err := repoRelativePath(repo.RootPath, args[0])
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	newPath, err := repoRelativePath(repo.RootPath, args[1])
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	manifest, err := converter.LoadManifest()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	result, err := operations.MoveFile(operations.MoveOptions{
+		RepoRoot:      repo.RootPath,
+		PathConverter: converter,
+		Manifest:      manifest,
+		OldPath:       oldPath,
+		NewPath:       newPath,
+	})
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	if err := converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	currentOutput.PrintSuccess("Moved: %s -> %s", result.OldPath, result.NewPath)
+	for _, path := range result.Linked {
+		currentOutput.PrintInfo("  linked %s", path)
+	}
+	for _, path := range result.Removed {
+		currentOutput.PrintInfo("  removed %s", path)
+	}
+	for _, warning := range result.Warnings {
+		currentOutput.PrintInfo("Warning: %s", warning)
+	}
+
+	return nil
+}
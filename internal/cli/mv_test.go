@@ -0,0 +1,90 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMvCommand(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+
+	apiFile := filepath.Join(repoDir, "pkg", "api", "CLAUDE.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(apiFile), 0755))
+	require.NoError(t, os.WriteFile(apiFile, []byte("api"), 0644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+
+	t.Run("LinkMovedWithDirectory", func(t *testing.T) {
+		require.NoError(t, os.Rename(filepath.Join(repoDir, "pkg", "api"), filepath.Join(repoDir, "pkg", "server")))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"status"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "run 'claude-md mv pkg/api/CLAUDE.md pkg/server/CLAUDE.md'")
+
+		stdout.Reset()
+		exitCode := cli.Run([]string{"mv", "pkg/api/CLAUDE.md", "pkg/server/CLAUDE.md"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Moved: pkg/api/CLAUDE.md -> pkg/server/CLAUDE.md")
+
+		_, err := os.Stat(filepath.Join(storageDir, "pkg~api~CLAUDE.md"))
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(filepath.Join(storageDir, ".history", "pkg~server~CLAUDE.md"))
+		assert.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(repoDir, "pkg", "server", "CLAUDE.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "api", string(content))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"status"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "linked       pkg/server/CLAUDE.md")
+	})
+
+	t.Run("MovesLink", func(t *testing.T) {
+		stdout.Reset()
+		exitCode := cli.Run([]string{"mv", "pkg/server/CLAUDE.md", "docs/CLAUDE.md"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+
+		_, err := os.Lstat(filepath.Join(repoDir, "pkg", "server", "CLAUDE.md"))
+		assert.True(t, os.IsNotExist(err))
+
+		newFile := filepath.Join(repoDir, "docs", "CLAUDE.md")
+		info, err := os.Lstat(newFile)
+		require.NoError(t, err)
+		assert.NotEqual(t, 0, info.Mode()&os.ModeSymlink)
+		content, err := os.ReadFile(newFile)
+		require.NoError(t, err)
+		assert.Equal(t, "api", string(content))
+	})
+
+	t.Run("RefusesUnmanaged", func(t *testing.T) {
+		var stderr bytes.Buffer
+		exitCode := cli.Run([]string{"mv", "pkg/CLAUDE.md", "lib/CLAUDE.md"}, cli.RunOptions{Stdout: &stdout, Stderr: &stderr})
+
+		assert.NotEqual(t, 0, exitCode)
+		assert.Contains(t, stderr.String(), "not managed by claude-md")
+	})
+
+	_ = os.RemoveAll(storageDir)
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// promptChoice asks the user to pick one of choices, re-asking until the answer is valid
+func promptChoice(question string, choices []string) (string, error) {
+	for {
+		_, _ = fmt.Fprintf(currentOutput.Stdout, "%s [%s]: ", question, strings.Join(choices, "/"))
+
+		line, err := currentInput.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		for _, choice := range choices {
+			if strings.EqualFold(answer, choice) {
+				return choice, nil
+			}
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", errors.New("no answer given")
+			}
+			return "", err
+		}
+		currentOutput.PrintInfo("Please answer one of: %s", strings.Join(choices, ", "))
+	}
+}
@@ -0,0 +1,525 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/git"
+	"github.com/kapetan-io/claude-md.go/internal/lock"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// repoContext holds what commands need to know about the current repository
+type repoContext struct {
+	Repo      *git.Repository
+	User      string
+	Converter *storage.PathConverter
+	locks     []*lock.Lock
+	readOnly  bool // Loaded by readRepoContext, without locks
+}
+
+// loadRepoContext detects the repository and its storage location, printing any error.
+// It locks the working tree and the repository's storage until Close, so concurrent
+// claude-md runs (a git hook and a manual run, or two worktrees) take turns.
+// Files whose restore was deferred are linked here once their directory exists, so
+// every command that touches a repository picks them up.
+func loadRepoContext() (*repoContext, error) {
+	ctx, err := detectRepoContext()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.open(); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// open does the work of loadRepoContext for a detected repository
+func (ctx *repoContext) open() error {
+	if err := ctx.lock(); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	ctx.loadPatterns()
+	registerClone(ctx)
+	linkPending(ctx)
+	return nil
+}
+
+// readRepoContext is loadRepoContext for commands that only read, such as
+// status. It takes no locks, so it never waits for a long running command,
+// and leaves the manifest and working tree alone.
+func readRepoContext() (*repoContext, error) {
+	ctx, err := detectRepoContext()
+	if err != nil {
+		return nil, err
+	}
+	ctx.readOnly = true
+	ctx.loadPatterns()
+	return ctx, nil
+}
+
+// errNotMapped is matched by the errors of a repository claude-md cannot map to
+// storage, one without user.email or a usable origin remote. Git hooks run in
+// every repository they are installed in, and leave such repositories alone.
+var errNotMapped = errors.New("repository is not mapped to claude-md storage")
+
+// notMappedError wraps the reason a repository cannot be mapped to storage
+type notMappedError struct {
+	err error
+}
+
+func (e *notMappedError) Error() string        { return e.err.Error() }
+func (e *notMappedError) Unwrap() error        { return e.err }
+func (e *notMappedError) Is(target error) bool { return target == errNotMapped }
+
+// detectRepoContext detects the repository and its storage location, printing
+// any error, without taking locks or touching the working tree. Git filters use
+// it directly, since they run inside git commands claude-md itself may start.
+func detectRepoContext() (*repoContext, error) {
+	ctx, err := findRepoContext()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// findRepoContext is detectRepoContext without printing. When the repository
+// cannot be mapped to storage the error matches errNotMapped, and the returned
+// context holds only the repository.
+func findRepoContext() (*repoContext, error) {
+	repo, err := git.FindRepository()
+	if err != nil {
+		return nil, err
+	}
+	ctx := &repoContext{Repo: repo}
+
+	email, err := repo.GetUserEmail()
+	if err != nil {
+		return ctx, &notMappedError{err}
+	}
+
+	ctx.User, err = git.ExtractUserFromEmail(email)
+	if err != nil {
+		return ctx, &notMappedError{err}
+	}
+
+	originURL, err := repo.GetOriginURL()
+	if err != nil {
+		return ctx, &notMappedError{err}
+	}
+
+	repoName, err := git.ExtractRepoName(originURL)
+	if err != nil {
+		return ctx, &notMappedError{err}
+	}
+
+	ctx.Converter, err = storage.NewPathConverter(ctx.User, repoName)
+	if err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// loadPatterns sets the file name patterns of the repository from its manifest.
+// Commands that fail on a corrupt manifest report it when they load it themselves.
+func (ctx *repoContext) loadPatterns() {
+	manifest, err := ctx.Converter.LoadManifest()
+	if err != nil {
+		return
+	}
+	ctx.Converter.Patterns = manifest.Patterns
+	if manifest.GetTrackedPolicy() == storage.TrackedLocal {
+		ctx.Converter.Patterns = files.WithLocalPatterns(manifest.Patterns)
+		// Companions pulled in by @import can have any name
+		for _, companion := range manifest.Overlays {
+			ctx.Converter.Patterns = append(ctx.Converter.Patterns, filepath.Base(companion))
+		}
+	}
+}
+
+// lock takes the working tree lock, then the storage lock, always in that order
+// The storage lock lives next to the repo storage directory so it can be taken
+// before the directory exists.
+func (ctx *repoContext) lock() error {
+	if err := ctx.lockWorktree(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(ctx.Converter.StorageRoot, 0700); err != nil {
+		ctx.Close()
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	l, err := lock.Acquire(ctx.Converter.GetRepoStorageDir()+".lock", lockTimeout)
+	if err != nil {
+		ctx.Close()
+		return err
+	}
+	ctx.locks = append(ctx.locks, l)
+	return nil
+}
+
+// lockWorktree takes the working tree lock, which lives in the git directory of
+// each worktree
+func (ctx *repoContext) lockWorktree() error {
+	gitDir, err := ctx.Repo.GitDir()
+	if err != nil {
+		return err
+	}
+	l, err := lock.Acquire(filepath.Join(gitDir, "claude-md.lock"), lockTimeout)
+	if err != nil {
+		return err
+	}
+	ctx.locks = append(ctx.locks, l)
+	return nil
+}
+
+// Close releases the locks taken by loadRepoContext
+func (ctx *repoContext) Close() {
+	for i := len(ctx.locks) - 1; i >= 0; i-- {
+		_ = ctx.locks[i].Release()
+	}
+	ctx.locks = nil
+}
+
+// registerClone records this working tree in the manifest so commands like
+// forget can find links in every clone. Nothing is written before init or save
+// created the storage directory.
+func registerClone(ctx *repoContext) {
+	if _, err := os.Stat(ctx.Converter.GetRepoStorageDir()); err != nil {
+		return
+	}
+
+	manifest, err := ctx.Converter.LoadManifest()
+	if err != nil || !manifest.AddClone(files.CanonicalPath(ctx.Repo.RootPath)) {
+		return
+	}
+	if err := ctx.Converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: failed to record clone: %v", err)
+	}
+}
+
+// linkPending restores deferred files whose parent directory now exists
+func linkPending(ctx *repoContext) {
+	manifest, err := ctx.Converter.LoadManifest()
+	if err != nil || len(manifest.Pending) == 0 {
+		return
+	}
+
+	storedFiles, err := files.FindStoredFiles(ctx.Converter.GetRepoStorageDir(), ctx.Converter)
+	if err != nil {
+		return
+	}
+
+	// The manifest supplies each file's link mode and style, and records placed copies
+	opts, err := withTracked(ctx, manifest, operations.RestoreOptions{
+		RepoRoot: ctx.Repo.RootPath,
+		Manifest: manifest,
+	})
+	if err != nil {
+		return
+	}
+	results := operations.RestorePending(storedFiles, manifest, opts)
+	if len(results) == 0 {
+		return
+	}
+
+	if err := ctx.Converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: failed to update pending restores: %v", err)
+		return
+	}
+
+	for _, result := range results {
+		if result.Success {
+			currentOutput.PrintSuccess("Restored pending: %s", result.RepoRelativePath)
+		} else if result.Warning != "" {
+			currentOutput.PrintInfo("Warning: %s", result.Warning)
+		}
+	}
+}
+
+// forEachWorktree runs fn for every working tree of the repository, starting
+// with the main one, under a heading naming it. Other worktrees share ctx's
+// storage, and storage lock, and have their working tree locked while fn runs
+// unless ctx is read only. Worktrees whose directory is gone are skipped.
+// Returns how many fn calls failed.
+func forEachWorktree(ctx *repoContext, fn func(wctx *repoContext) error) (int, error) {
+	worktrees, err := ctx.Repo.Worktrees()
+	if err != nil {
+		return 0, err
+	}
+
+	var failed int
+	current := files.CanonicalPath(ctx.Repo.RootPath)
+	for i, worktree := range worktrees {
+		if worktree.Bare {
+			continue
+		}
+		if i > 0 {
+			currentOutput.PrintInfo("")
+		}
+		if worktree.Prunable {
+			currentOutput.PrintInfo("Warning: Skipping worktree %s: its directory no longer exists "+
+				"(run 'git worktree prune')", worktree.Path)
+			continue
+		}
+		branch := worktree.Branch
+		if branch == "" {
+			branch = "detached HEAD"
+		}
+		currentOutput.PrintInfo("Worktree: %s (%s)", worktree.Path, branch)
+
+		if files.CanonicalPath(worktree.Path) == current {
+			if err := fn(ctx); err != nil {
+				failed++
+			}
+			continue
+		}
+
+		wctx := &repoContext{Repo: &git.Repository{RootPath: worktree.Path}, User: ctx.User,
+			Converter: ctx.Converter, readOnly: ctx.readOnly}
+		if !ctx.readOnly {
+			if err := wctx.lockWorktree(); err != nil {
+				failed++
+				currentOutput.PrintError("Error: %v", err)
+				continue
+			}
+			registerClone(wctx)
+		}
+		if err := fn(wctx); err != nil {
+			failed++
+		}
+		wctx.Close()
+	}
+	return failed, nil
+}
+
+// findManagedFiles finds the managed files in the working tree and marks the
+// ones git tracks
+func findManagedFiles(ctx *repoContext) ([]files.ClaudeFile, error) {
+	claudeFiles, err := files.FindManagedFiles(ctx.Repo.RootPath, ctx.Converter.Patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked, err := ctx.Repo.TrackedPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	// A working tree can contain storage itself, as a dotfiles repository of
+	// the home directory does
+	storageDir := ctx.Converter.GetStorageBaseDir()
+	managed := claudeFiles[:0]
+	for _, file := range claudeFiles {
+		// Links into storage live outside it, so only their directory is resolved
+		if files.IsWithin(filepath.Dir(file.AbsolutePath), storageDir) {
+			continue
+		}
+		file.Tracked = tracked[filepath.ToSlash(file.RepoRelativePath)]
+		managed = append(managed, file)
+	}
+	return managed, nil
+}
+
+// findOverlays returns the personal companion of each tracked file in claudeFiles
+// when the repository manages overlays, nil otherwise
+func findOverlays(ctx *repoContext, manifest *storage.Manifest, claudeFiles []files.ClaudeFile) ([]operations.Overlay, error) {
+	if manifest.GetTrackedPolicy() != storage.TrackedLocal {
+		return nil, nil
+	}
+	tracked, err := ctx.Repo.TrackedPaths()
+	if err != nil {
+		return nil, err
+	}
+	return operations.FindOverlays(claudeFiles, ctx.Repo.RootPath, tracked), nil
+}
+
+// withTracked fills in how opts treats files git tracks, following the
+// manifest's tracked file policy
+func withTracked(ctx *repoContext, manifest *storage.Manifest, opts operations.RestoreOptions) (operations.RestoreOptions, error) {
+	tracked, err := ctx.Repo.TrackedPaths()
+	if err != nil {
+		return opts, err
+	}
+	opts.Tracked = manifest.GetTrackedPolicy()
+	opts.TrackedPaths = tracked
+	opts.SetSkipWorktree = ctx.Repo.SetSkipWorktree
+	return opts, nil
+}
+
+// releaseTracked clears the skip-worktree bit of tracked files that are no
+// longer linked, and with checkout puts back the committed file where the link was
+func releaseTracked(ctx *repoContext, repoRelativePaths []string, checkout bool) {
+	if len(repoRelativePaths) == 0 {
+		return
+	}
+	tracked, err := ctx.Repo.TrackedPaths()
+	if err != nil {
+		return
+	}
+
+	for _, path := range repoRelativePaths {
+		path = filepath.ToSlash(path)
+		if !tracked[path] {
+			continue
+		}
+		if err := ctx.Repo.SetSkipWorktree(path, false); err != nil {
+			currentOutput.PrintError("Error: %s: %v", path, err)
+			continue
+		}
+		if checkout {
+			if err := ctx.Repo.CheckoutFromIndex(path); err != nil {
+				currentOutput.PrintError("Error: %s: %v", path, err)
+				continue
+			}
+			currentOutput.PrintInfo("Checked out: %s (committed version)", path)
+		}
+	}
+}
+
+// excludeFilePath returns the file that lists linked paths for git to ignore
+func excludeFilePath(repo *git.Repository, manifest *storage.Manifest) (string, error) {
+	switch {
+	case manifest.ExcludeFile == "":
+		return repo.InfoExcludePath()
+	case filepath.IsAbs(manifest.ExcludeFile):
+		return manifest.ExcludeFile, nil
+	default:
+		return filepath.Join(repo.RootPath, manifest.ExcludeFile), nil
+	}
+}
+
+// syncExcludes lists the files linked into the working tree in the exclude
+// file, so git status does not offer them for commit. The block is removed
+// once nothing is linked. Worktrees share info/exclude, so a shared file lists
+// the files linked into every worktree.
+func syncExcludes(ctx *repoContext) {
+	manifest, err := ctx.Converter.LoadManifest()
+	if err != nil {
+		return
+	}
+
+	storedFiles, err := files.FindStoredFiles(ctx.Converter.GetRepoStorageDir(), ctx.Converter)
+	if err != nil {
+		return
+	}
+
+	// A work tree set with GIT_WORK_TREE is not listed as a worktree
+	roots := []string{ctx.Repo.RootPath}
+	if worktrees, err := ctx.Repo.Worktrees(); err == nil {
+		current := files.CanonicalPath(ctx.Repo.RootPath)
+		for _, worktree := range worktrees {
+			if !worktree.Bare && !worktree.Prunable && files.CanonicalPath(worktree.Path) != current {
+				roots = append(roots, worktree.Path)
+			}
+		}
+	}
+
+	// Exclude files by canonical path, each with the paths linked in any worktree using it
+	var excludePaths []string
+	linked := make(map[string]map[string]bool)
+	for _, root := range roots {
+		excludePath, err := excludeFilePath(&git.Repository{RootPath: root}, manifest)
+		if err != nil {
+			currentOutput.PrintError("Error: failed to update git excludes: %v", err)
+			return
+		}
+		key := files.CanonicalPath(excludePath)
+		if linked[key] == nil {
+			linked[key] = make(map[string]bool)
+			excludePaths = append(excludePaths, excludePath)
+		}
+		for _, path := range operations.ExcludedPaths(storedFiles, operations.StatusOptions{
+			RepoRoot: root,
+			Manifest: manifest,
+		}) {
+			linked[key][path] = true
+		}
+	}
+
+	for _, excludePath := range excludePaths {
+		var paths []string
+		for path := range linked[files.CanonicalPath(excludePath)] {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		if _, err := operations.WriteExcludeBlock(excludePath, paths); err != nil {
+			currentOutput.PrintError("Error: failed to update git excludes: %v", err)
+		}
+	}
+}
+
+// repoRelativePath converts a path given on the command line, relative to the
+// current directory, into a slash separated path relative to the repository root
+func repoRelativePath(repoRoot, arg string) (string, error) {
+	abs, err := filepath.Abs(arg)
+	if err != nil {
+		return "", err
+	}
+	// Resolve the directory only, the path itself may be a link into storage
+	if info, err := os.Lstat(abs); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		abs = filepath.Join(files.CanonicalPath(filepath.Dir(abs)), filepath.Base(abs))
+	} else {
+		abs = files.CanonicalPath(abs)
+	}
+	rel, err := filepath.Rel(files.CanonicalPath(repoRoot), abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository", arg)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// nestedRepository returns the repo relative path of the submodule or nested
+// repository that contains repoPath, or "" when it belongs to the repository itself
+func nestedRepository(repoRoot, repoPath string) string {
+	dir := ""
+	parts := strings.Split(repoPath, "/")
+	for _, part := range parts[:len(parts)-1] {
+		dir = path.Join(dir, part)
+		if files.IsRepositoryRoot(filepath.Join(repoRoot, filepath.FromSlash(dir))) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// selectStoredFiles returns the stored files named by args, where a directory
+// selects every stored file below it. No args selects all stored files.
+func selectStoredFiles(storedFiles []files.StoredFile, repoRoot string, args []string) ([]files.StoredFile, error) {
+	if len(args) == 0 {
+		return storedFiles, nil
+	}
+
+	var selected []files.StoredFile
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		rel, err := repoRelativePath(repoRoot, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for _, stored := range storedFiles {
+			if files.UnderPath(stored.RepoRelativePath, rel) {
+				found = true
+				if !seen[stored.StorageFilename] {
+					seen[stored.StorageFilename] = true
+					selected = append(selected, stored)
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s is not managed by claude-md", arg)
+		}
+	}
+	return selected, nil
+}
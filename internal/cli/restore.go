@@ -1,15 +1,18 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"io"
+
 	"github.com/kapetan-io/claude-md.go/internal/files"
-	"github.com/kapetan-io/claude-md.go/internal/git"
 	"github.com/kapetan-io/claude-md.go/internal/operations"
-	"github.com/kapetan-io/claude-md.go/internal/storage"
+	"github.com/kapetan-io/claude-md.go/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var restoreCmd = &cobra.Command{
-	Use:   "restore",
+	Use:   "restore [paths...]",
 	Short: "Restore CLAUDE.md files from storage",
 	Long: `Creates symlinks for all stored CLAUDE.md files in the repository.
 
@@ -17,75 +20,220 @@ This command will:
 1. Find all stored CLAUDE.md files for this repository
 2. Create symlinks in the appropriate locations pointing to storage
 
-Files that already exist (regular files or symlinks) are skipped with a warning.
-If a parent directory doesn't exist, the file is skipped with a warning.`,
+Existing symlinks are skipped with a warning. When a regular file is in the way,
+--on-conflict decides what happens:
+  skip             Leave the file alone and warn (default)
+  adopt-identical  Replace the file with the symlink if it is byte-identical to storage
+  replace          Replace the file with the symlink, discarding its content
+  backup           Move the file to .backups/<name>.<timestamp> in storage, then link
+  prompt           Ask for each conflicting file
+
+If a parent directory doesn't exist, the file is skipped with a warning. Use
+--create-parents to create missing directories, or --defer to record the file as
+pending; pending files are linked by the next claude-md command that finds their
+directory in place (for example after switching to a branch that has it).
+
+Paths limit the command to the given files or directories. --include and
+--exclude take globs over repo relative paths, where ** matches any number of
+directories and a glob naming a directory selects everything below it.
+
+--quiet prints only errors, as the git hooks installed by 'claude-md hooks' do.
+A repository claude-md cannot map to storage, one without user.email or an
+origin remote, is not an error then, and nothing is printed.
+
+--all-worktrees restores into every worktree listed by 'git worktree list',
+which all share this repository's storage. Paths and filters select the same
+repo relative files in each.`,
 	Example: `  # Restore all CLAUDE.md files for current repository
-  claude-md restore`,
+  claude-md restore
+
+  # Link files a tool wrote back with the same content
+  claude-md restore --on-conflict=adopt-identical
+
+  # Link files whose directories only exist on other branches once they appear
+  claude-md restore --defer
+
+  # Restore just the root file
+  claude-md restore CLAUDE.md
+
+  # Restore into every worktree of the repository
+  claude-md restore --all-worktrees`,
 	RunE: runRestore,
 }
 
+var (
+	restoreOnConflict    string
+	restoreCreateParents bool
+	restoreDeferMissing  bool
+	restoreLinkMode      string
+	restoreLinkStyle     string
+	restoreFilter        filterFlags
+	restoreQuiet         bool
+	restoreAllWorktrees  bool
+)
+
 func init() {
 	rootCmd.AddCommand(restoreCmd)
+	addRecurseSubmodulesFlag(restoreCmd)
+	restoreCmd.Flags().StringVar(&restoreOnConflict, "on-conflict", string(operations.RestoreConflictSkip),
+		"what to do when a regular file is in the way: skip, adopt-identical, replace, backup or prompt")
+	restoreCmd.Flags().BoolVar(&restoreCreateParents, "create-parents", false,
+		"create missing parent directories")
+	restoreCmd.Flags().BoolVar(&restoreDeferMissing, "defer", false,
+		"record files whose parent directory is missing and link them once it exists")
+	restoreCmd.Flags().StringVar(&restoreLinkMode, "link-mode", "",
+		"place files as a symlink, hardlink or copy (default: the repository setting)")
+	restoreCmd.Flags().StringVar(&restoreLinkStyle, "link-style", "",
+		"create absolute or relative symlinks (default: the repository setting)")
+	addFilterFlags(restoreCmd, &restoreFilter)
+	restoreCmd.Flags().BoolVarP(&restoreQuiet, "quiet", "q", false,
+		"only print errors, for use in git hooks")
+	restoreCmd.Flags().BoolVar(&restoreAllWorktrees, "all-worktrees", false,
+		"restore into every worktree of the repository")
 }
 
 func runRestore(cmd *cobra.Command, args []string) error {
-	repo, err := git.FindRepository()
+	if restoreQuiet {
+		previous := currentOutput
+		currentOutput = output.NewOutput(io.Discard, previous.Stderr)
+		defer func() { currentOutput = previous }()
+	}
+
+	onConflict, err := operations.ParseRestoreConflictPolicy(restoreOnConflict)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	email, err := repo.GetUserEmail()
+	linkMode, err := parseLinkModeFlag(restoreLinkMode)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	user, err := git.ExtractUserFromEmail(email)
+	linkStyle, err := parseLinkStyleFlag(restoreLinkStyle)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	originURL, err := repo.GetOriginURL()
+	var ctx *repoContext
+	if restoreQuiet {
+		// Hooks run in every repository, those without storage are left alone
+		ctx, err = findRepoContext()
+		if errors.Is(err, errNotMapped) {
+			return nil
+		}
+		if err != nil {
+			currentOutput.PrintError("Error: %v", err)
+			return err
+		}
+		if err := ctx.open(); err != nil {
+			return err
+		}
+	} else if ctx, err = loadRepoContext(); err != nil {
+		return err
+	}
+	defer ctx.Close()
+	defer syncExcludes(ctx)
+	repo, converter := ctx.Repo, ctx.Converter
+
+	repoStorageDir := converter.GetRepoStorageDir()
+	storedFiles, err := files.FindStoredFiles(repoStorageDir, converter)
 	if err != nil {
-		currentOutput.PrintError("Error: %v", err)
+		currentOutput.PrintError("Error finding stored files: %v", err)
 		return err
 	}
 
-	repoName, err := git.ExtractRepoName(originURL)
+	filter, err := buildFilter(repo.RootPath, args, restoreFilter)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
+	storedFiles = filter.StoredFiles(storedFiles)
+
+	if len(storedFiles) == 0 {
+		currentOutput.PrintInfo("No stored CLAUDE.md files found for this repository")
+		return nil
+	}
+
+	opts := operations.RestoreOptions{
+		OnConflict:    onConflict,
+		CreateParents: restoreCreateParents,
+		Defer:         restoreDeferMissing,
+		LinkMode:      linkMode,
+		LinkStyle:     linkStyle,
+		Ejected:       len(args) > 0,
+		Prompt:        promptRestoreConflict,
+	}
+	if !restoreAllWorktrees {
+		return restoreWorktree(ctx, storedFiles, opts)
+	}
 
-	converter, err := storage.NewPathConverter(user, repoName)
+	failed, err := forEachWorktree(ctx, func(wctx *repoContext) error {
+		return restoreWorktree(wctx, storedFiles, opts)
+	})
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
+	if failed > 0 {
+		return fmt.Errorf("restore failed in %d worktrees", failed)
+	}
+	return nil
+}
 
-	repoStorageDir := converter.GetRepoStorageDir()
-	storedFiles, err := files.FindStoredFiles(repoStorageDir, converter)
+// restoreWorktree links storedFiles into the working tree of ctx and prints
+// what happened
+func restoreWorktree(ctx *repoContext, storedFiles []files.StoredFile, opts operations.RestoreOptions) error {
+	repo, converter := ctx.Repo, ctx.Converter
+
+	manifest, err := converter.LoadManifest()
 	if err != nil {
-		currentOutput.PrintError("Error finding stored files: %v", err)
+		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	if len(storedFiles) == 0 {
-		currentOutput.PrintInfo("No stored CLAUDE.md files found for this repository")
-		return nil
+	opts.RepoRoot = repo.RootPath
+	opts.Manifest = manifest
+	opts.PathConverter = converter
+	opts, err = withTracked(ctx, manifest, opts)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
 	}
+	results := operations.RestoreFiles(storedFiles, opts)
 
-	results := operations.RestoreFiles(storedFiles, operations.RestoreOptions{
-		RepoRoot: repo.RootPath,
-	})
+	for _, result := range results {
+		if result.Deferred {
+			manifest.AddPending(result.RepoRelativePath)
+		}
+	}
+	if err := converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
 
 	var restored, skipped, warnings int
 	for _, result := range results {
 		if result.Success {
 			restored++
-			currentOutput.PrintSuccess("Restored: %s", result.RepoRelativePath)
+			switch {
+			case result.Resolution == operations.RestoreConflictBackup:
+				currentOutput.PrintSuccess("Restored: %s (existing file moved to %s)",
+					result.RepoRelativePath, result.BackupPath)
+			case result.Resolution == operations.RestoreConflictAdoptIdentical:
+				currentOutput.PrintSuccess("Restored: %s (adopted identical file)", result.RepoRelativePath)
+			case result.Resolution == operations.RestoreConflictReplace:
+				currentOutput.PrintSuccess("Restored: %s (replaced existing file)", result.RepoRelativePath)
+			case result.CreatedParents:
+				currentOutput.PrintSuccess("Restored: %s (created parent directories)", result.RepoRelativePath)
+			default:
+				currentOutput.PrintSuccess("Restored: %s", result.RepoRelativePath)
+			}
+		} else if result.Deferred {
+			skipped++
+			currentOutput.PrintInfo("Deferred: %s (parent directory does not exist yet)", result.RepoRelativePath)
 		} else if result.Skipped {
 			skipped++
 			if result.Warning != "" {
@@ -99,3 +247,15 @@ func runRestore(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// promptRestoreConflict asks how to handle a regular file where a symlink should go
+func promptRestoreConflict(stored files.StoredFile, identical bool) (operations.RestoreConflictPolicy, error) {
+	state := "differs from"
+	if identical {
+		state = "is identical to"
+	}
+	choice, err := promptChoice(
+		fmt.Sprintf("%s is a regular file that %s storage. Resolve with", stored.RepoRelativePath, state),
+		[]string{"skip", "replace", "backup"})
+	return operations.RestoreConflictPolicy(choice), err
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/kapetan-io/claude-md.go/internal/cli"
@@ -121,3 +122,250 @@ func TestRestoreCommandErrorCases(t *testing.T) {
 	require.Equal(t, 1, exitCode)
 	assert.Contains(t, stderr.String(), "Error")
 }
+
+func TestRestoreCommandOnConflict(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+
+	// setup stores "stored\n" and leaves a regular file holding existing in the repo
+	setup := func(t *testing.T, existing string) {
+		_ = os.RemoveAll(storageDir)
+		_ = os.Remove(claudeFile)
+		require.NoError(t, os.WriteFile(claudeFile, []byte("stored\n"), 0644))
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+		require.NoError(t, os.Remove(claudeFile))
+		require.NoError(t, os.WriteFile(claudeFile, []byte(existing), 0644))
+	}
+
+	isSymlink := func(t *testing.T) bool {
+		info, err := os.Lstat(claudeFile)
+		require.NoError(t, err)
+		return info.Mode()&os.ModeSymlink != 0
+	}
+
+	t.Run("AdoptIdentical", func(t *testing.T) {
+		setup(t, "stored\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"restore", "--on-conflict=adopt-identical"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Restored: CLAUDE.md (adopted identical file)")
+		assert.True(t, isSymlink(t))
+		leftovers, err := filepath.Glob(claudeFile + ".*.bak")
+		require.NoError(t, err)
+		assert.Empty(t, leftovers)
+	})
+
+	t.Run("AdoptIdenticalSkipsDifferent", func(t *testing.T) {
+		setup(t, "changed\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"restore", "--on-conflict=adopt-identical"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "regular file differs from storage")
+		assert.False(t, isSymlink(t))
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		setup(t, "changed\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"restore", "--on-conflict=replace"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Restored: CLAUDE.md (replaced existing file)")
+		assert.True(t, isSymlink(t))
+	})
+
+	t.Run("Backup", func(t *testing.T) {
+		setup(t, "changed\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"restore", "--on-conflict=backup"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "existing file moved to")
+		assert.True(t, isSymlink(t))
+
+		// The replaced file is kept in storage, not left in the working tree
+		leftovers, err := filepath.Glob(claudeFile + ".*")
+		require.NoError(t, err)
+		assert.Empty(t, leftovers)
+		backups, err := filepath.Glob(filepath.Join(storageDir, ".backups", "CLAUDE.md.*"))
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		assert.Contains(t, stdout.String(), "existing file moved to "+backups[0])
+		content, err := os.ReadFile(backups[0])
+		require.NoError(t, err)
+		assert.Equal(t, "changed\n", string(content))
+	})
+
+	t.Run("Prompt", func(t *testing.T) {
+		setup(t, "changed\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"restore", "--on-conflict=prompt"}, cli.RunOptions{
+			Stdin:  strings.NewReader("skip\n"),
+			Stdout: &stdout,
+		})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "differs from storage")
+		assert.False(t, isSymlink(t))
+	})
+
+	_ = os.RemoveAll(storageDir)
+}
+
+func TestRestoreCommandMissingParents(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	nestedDir := filepath.Join(repoDir, "services", "api")
+	nestedFile := filepath.Join(nestedDir, "CLAUDE.md")
+
+	// setup stores services/api/CLAUDE.md and removes its directory from the repo
+	setup := func(t *testing.T) {
+		_ = os.RemoveAll(storageDir)
+		_ = os.RemoveAll(filepath.Join(repoDir, "services"))
+		require.NoError(t, os.MkdirAll(nestedDir, 0755))
+		require.NoError(t, os.WriteFile(nestedFile, []byte("nested"), 0644))
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+		require.NoError(t, os.RemoveAll(filepath.Join(repoDir, "services")))
+	}
+
+	t.Run("SkipsByDefault", func(t *testing.T) {
+		setup(t)
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "parent directory does not exist")
+		assert.NoDirExists(t, nestedDir)
+	})
+
+	t.Run("CreateParents", func(t *testing.T) {
+		setup(t)
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"restore", "--create-parents"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Restored: services/api/CLAUDE.md (created parent directories)")
+		content, err := os.ReadFile(nestedFile)
+		require.NoError(t, err)
+		assert.Equal(t, "nested", string(content))
+	})
+
+	t.Run("Defer", func(t *testing.T) {
+		setup(t)
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"restore", "--defer"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Deferred: services/api/CLAUDE.md")
+		assert.NoDirExists(t, nestedDir)
+
+		// Nothing happens while the directory is still missing
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"init"}, cli.RunOptions{Stdout: &stdout}))
+		assert.NotContains(t, stdout.String(), "Restored pending")
+
+		// Any later command links the file once the directory shows up
+		require.NoError(t, os.MkdirAll(nestedDir, 0755))
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"init"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Restored pending: services/api/CLAUDE.md")
+
+		info, err := os.Lstat(nestedFile)
+		require.NoError(t, err)
+		assert.NotEqual(t, 0, info.Mode()&os.ModeSymlink)
+
+		// The file is no longer pending
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"init"}, cli.RunOptions{Stdout: &stdout}))
+		assert.NotContains(t, stdout.String(), "Restored pending")
+	})
+
+	t.Run("DeferKeepsLinkMode", func(t *testing.T) {
+		setup(t)
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"init", "--link-mode=copy"}, cli.RunOptions{Stdout: &stdout}))
+		require.Equal(t, 0, cli.Run([]string{"restore", "--defer"}, cli.RunOptions{Stdout: &stdout}))
+
+		require.NoError(t, os.MkdirAll(nestedDir, 0755))
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"init"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Restored pending: services/api/CLAUDE.md")
+
+		info, err := os.Lstat(nestedFile)
+		require.NoError(t, err)
+		assert.True(t, info.Mode().IsRegular())
+
+		// The copy's base is recorded, so sync pushes an edit instead of flagging a conflict
+		require.NoError(t, os.WriteFile(nestedFile, []byte("edited"), 0644))
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"sync"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Pushed: services/api/CLAUDE.md")
+	})
+
+	_ = os.RemoveAll(storageDir)
+}
+
+func TestRestoreCommandQuiet(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+	defer func() { _ = os.RemoveAll(storageDir) }()
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("test content"), 0644))
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &bytes.Buffer{}}))
+	require.NoError(t, os.Remove(claudeFile))
+
+	var stdout bytes.Buffer
+	exitCode := cli.Run([]string{"restore", "--quiet"}, cli.RunOptions{Stdout: &stdout})
+
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stdout.String())
+	info, err := os.Lstat(claudeFile)
+	require.NoError(t, err)
+	assert.NotEqual(t, 0, info.Mode()&os.ModeSymlink)
+}
@@ -1,6 +1,10 @@
 package cli
 
 import (
+	"os"
+	"time"
+
+	"github.com/kapetan-io/claude-md.go/internal/lock"
 	"github.com/spf13/cobra"
 )
 
@@ -10,6 +14,7 @@ var rootCmd = &cobra.Command{
 	Long: `claude-md is a CLI tool for managing CLAUDE.md files using centralized storage with symlinks.
 It enables saving CLAUDE.md files from anywhere in a repository to a structured storage location
 and restoring them as symlinks.`,
+	PersistentPreRunE: changeDirectory,
 }
 
 // Execute runs the root command
@@ -17,6 +22,29 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// workDir is the directory given with -C, where commands run instead of the current one
+var workDir string
+
+// changeDirectory moves into the directory given with -C before any command
+// runs, so the repository and path arguments are found relative to it, as git -C does
+func changeDirectory(cmd *cobra.Command, args []string) error {
+	if workDir == "" {
+		return nil
+	}
+	if err := os.Chdir(workDir); err != nil {
+		currentOutput.PrintError("Error: cannot change to %s: %v", workDir, err)
+		return err
+	}
+	return nil
+}
+
+// lockTimeout is how long a command waits for another claude-md working on the same repository
+var lockTimeout time.Duration
+
 func init() {
 	// Add subcommands here as they are created
+	rootCmd.PersistentFlags().StringVarP(&workDir, "directory", "C", "",
+		"run as if claude-md was started in this directory")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", lock.DefaultTimeout,
+		"how long to wait for another claude-md working on the same repository")
 }
@@ -1,17 +1,24 @@
 package cli
 
 import (
+	"bufio"
 	"io"
 	"os"
 
 	"github.com/kapetan-io/claude-md.go/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // Package-level variable for commands to access output
 var currentOutput *output.Output
 
+// Package-level variable for commands that prompt for input
+var currentInput *bufio.Reader
+
 // RunOptions provides injectable dependencies for testing
 type RunOptions struct {
+	Stdin  io.Reader
 	Stdout io.Writer
 	Stderr io.Writer
 }
@@ -19,6 +26,9 @@ type RunOptions struct {
 // Run executes the CLI with given arguments and options
 func Run(args []string, opts RunOptions) int {
 	// Set defaults
+	if opts.Stdin == nil {
+		opts.Stdin = os.Stdin
+	}
 	if opts.Stdout == nil {
 		opts.Stdout = os.Stdout
 	}
@@ -28,10 +38,20 @@ func Run(args []string, opts RunOptions) int {
 
 	// Make output available to commands
 	currentOutput = output.NewOutput(opts.Stdout, opts.Stderr)
+	currentInput = bufio.NewReader(opts.Stdin)
 
 	// Configure Cobra's output streams (for help text, errors)
 	rootCmd.SetOut(opts.Stdout)
 	rootCmd.SetErr(opts.Stderr)
+	rootCmd.SetIn(opts.Stdin)
+
+	// Flag values live in package variables, so clear anything a previous Run parsed
+	resetFlags(rootCmd)
+
+	// -C changes the working directory, put it back for whatever runs next
+	if dir, err := os.Getwd(); err == nil {
+		defer func() { _ = os.Chdir(dir) }()
+	}
 
 	// Let Cobra parse args and route to commands
 	rootCmd.SetArgs(args)
@@ -42,3 +62,20 @@ func Run(args []string, opts RunOptions) int {
 	}
 	return 0
 }
+
+// resetFlags restores every flag of cmd and its subcommands to its default value
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
@@ -2,6 +2,9 @@ package cli_test
 
 import (
 	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/kapetan-io/claude-md.go/internal/cli"
@@ -51,3 +54,79 @@ func TestRunWithDefaultWriters(t *testing.T) {
 
 	require.Equal(t, 0, exitCode)
 }
+
+func TestRunWithDirectoryFlag(t *testing.T) {
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+	defer func() { _ = os.RemoveAll(storageDir) }()
+
+	repoDir := cli.SetupTestGitRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "docs", "CLAUDE.md"), []byte("docs"), 0644))
+
+	elsewhere := t.TempDir()
+	require.NoError(t, os.Chdir(elsewhere))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"-C", repoDir, "save"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Saved: docs/CLAUDE.md")
+
+	// Paths are relative to the -C directory, and the current directory is kept
+	require.NoError(t, os.Remove(filepath.Join(repoDir, "docs", "CLAUDE.md")))
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"-C", filepath.Join(repoDir, "docs"), "restore", "CLAUDE.md"},
+		cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Restored: docs/CLAUDE.md")
+	dir, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, elsewhere, dir)
+
+	var stderr bytes.Buffer
+	assert.Equal(t, 1, cli.Run([]string{"-C", filepath.Join(elsewhere, "missing"), "status"},
+		cli.RunOptions{Stdout: &stdout, Stderr: &stderr}))
+	assert.Contains(t, stderr.String(), "cannot change to")
+}
+
+func TestRunWithSeparateGitDir(t *testing.T) {
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "dotfiles.git")
+	_ = os.RemoveAll(storageDir)
+	defer func() { _ = os.RemoveAll(storageDir) }()
+
+	// A dotfiles style repository: the git directory lives apart from its working tree
+	gitDir := filepath.Join(t.TempDir(), "dotfiles.git")
+	workTree := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--bare", gitDir},
+		{"--git-dir", gitDir, "config", "user.email", "test@example.com"},
+		{"--git-dir", gitDir, "remote", "add", "origin", "https://github.com/test/dotfiles.git"},
+	} {
+		output, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	t.Setenv("GIT_DIR", gitDir)
+	t.Setenv("GIT_WORK_TREE", workTree)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(workTree, "notes"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workTree, "notes", "CLAUDE.md"), []byte("notes"), 0644))
+	require.NoError(t, os.Chdir(filepath.Join(workTree, "notes")))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Saved: notes/CLAUDE.md")
+
+	exclude, err := os.ReadFile(filepath.Join(gitDir, "info", "exclude"))
+	require.NoError(t, err)
+	assert.Contains(t, string(exclude), "/notes/CLAUDE.md\n")
+}
@@ -1,15 +1,17 @@
 package cli
 
 import (
+	"fmt"
+	"path/filepath"
+
 	"github.com/kapetan-io/claude-md.go/internal/files"
-	"github.com/kapetan-io/claude-md.go/internal/git"
 	"github.com/kapetan-io/claude-md.go/internal/operations"
 	"github.com/kapetan-io/claude-md.go/internal/storage"
 	"github.com/spf13/cobra"
 )
 
 var saveCmd = &cobra.Command{
-	Use:   "save",
+	Use:   "save [paths...]",
 	Short: "Save CLAUDE.md files to storage",
 	Long: `Finds all CLAUDE.md files in the repository and converts them to symlinks pointing to centralized storage.
 
@@ -18,60 +20,119 @@ This command will:
 2. Copy each file to ~/.claude/claude-md/<user>/<repo>/
 3. Replace the original file with a symlink to the stored copy
 
-Files already converted to symlinks are skipped. If a storage file already exists,
-the operation is skipped with a warning.`,
+Files already converted to symlinks are skipped, as are hardlinks and copies
+placed by claude-md (use 'claude-md sync' to reconcile those), unless --link-mode
+asks to place them another way. If a storage file already exists,
+--on-conflict decides what happens:
+  skip       Leave the file alone and warn (default)
+  overwrite  Replace the stored copy, keeping a backup in storage
+  keep-both  Keep the stored copy and store the repo file as a backup in storage
+  merge      Three-way merge against the last saved version, leaving conflict markers
+  prompt     Ask for each conflicting file
+
+Editors that save by renaming a temp file over the target replace the symlink
+with a regular file. With --update, a regular file that is newer than (or identical
+to) its stored copy is copied back into storage, the previous stored version is
+kept in history, and the file is linked again.
+
+Paths limit the command to the given files or directories. --include and
+--exclude take globs over repo relative paths, where ** matches any number of
+directories and a glob naming a directory selects everything below it.`,
 	Example: `  # Save all CLAUDE.md files in current repository
-  claude-md save`,
+  claude-md save
+
+  # Merge files that were edited both in storage and in the repository
+  claude-md save --on-conflict=merge
+
+  # Re-absorb files whose symlink was replaced by an editor
+  claude-md save --update
+
+  # Save only the files below services, except vendored ones
+  claude-md save services --exclude='**/vendor/**'`,
 	RunE: runSave,
 }
 
+var (
+	saveOnConflict string
+	saveUpdate     bool
+	saveLinkMode   string
+	saveLinkStyle  string
+	saveFilter     filterFlags
+)
+
 func init() {
 	rootCmd.AddCommand(saveCmd)
+	addRecurseSubmodulesFlag(saveCmd)
+	saveCmd.Flags().StringVar(&saveOnConflict, "on-conflict", string(operations.SaveConflictSkip),
+		"what to do when storage already has the file: skip, overwrite, keep-both, merge or prompt")
+	saveCmd.Flags().BoolVar(&saveUpdate, "update", false,
+		"re-absorb regular files that replaced their symlink and are newer than the stored copy")
+	saveCmd.Flags().StringVar(&saveLinkMode, "link-mode", "",
+		"place saved files as a symlink, hardlink or copy (default: the repository setting)")
+	saveCmd.Flags().StringVar(&saveLinkStyle, "link-style", "",
+		"create absolute or relative symlinks (default: the repository setting)")
+	addFilterFlags(saveCmd, &saveFilter)
 }
 
 func runSave(cmd *cobra.Command, args []string) error {
-	repo, err := git.FindRepository()
+	onConflict, err := operations.ParseSaveConflictPolicy(saveOnConflict)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	email, err := repo.GetUserEmail()
+	linkMode, err := parseLinkModeFlag(saveLinkMode)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	user, err := git.ExtractUserFromEmail(email)
+	linkStyle, err := parseLinkStyleFlag(saveLinkStyle)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	originURL, err := repo.GetOriginURL()
+	ctx, err := loadRepoContext()
 	if err != nil {
-		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
+	defer ctx.Close()
+	defer syncExcludes(ctx)
+	repo, converter := ctx.Repo, ctx.Converter
 
-	repoName, err := git.ExtractRepoName(originURL)
+	filter, err := buildFilter(repo.RootPath, args, saveFilter)
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	converter, err := storage.NewPathConverter(user, repoName)
+	manifest, err := converter.LoadManifest()
 	if err != nil {
 		currentOutput.PrintError("Error: %v", err)
 		return err
 	}
 
-	claudeFiles, err := files.FindClaudeFiles(repo.RootPath)
+	claudeFiles, err := findManagedFiles(ctx)
 	if err != nil {
 		currentOutput.PrintError("Error finding CLAUDE.md files: %v", err)
 		return err
 	}
 
+	// Personal overlays are saved in place of the tracked files they sit next to
+	overlays, err := findOverlays(ctx, manifest, claudeFiles)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	claudeFiles = append(claudeFiles, operations.CompanionFiles(overlays, claudeFiles, repo.RootPath)...)
+	companions := make(map[string]string, len(overlays))
+	for _, overlay := range overlays {
+		companions[overlay.TrackedPath] = overlay.CompanionPath
+	}
+
+	claudeFiles = filter.ClaudeFiles(claudeFiles)
+
 	if len(claudeFiles) == 0 {
 		currentOutput.PrintInfo("No CLAUDE.md files found in repository")
 		return nil
@@ -80,13 +141,65 @@ func runSave(cmd *cobra.Command, args []string) error {
 	results := operations.SaveFiles(claudeFiles, operations.SaveOptions{
 		RepoRoot:      repo.RootPath,
 		PathConverter: converter,
+		Manifest:      manifest,
+		OnConflict:    onConflict,
+		Update:        saveUpdate,
+		Ejected:       len(args) > 0,
+		LinkMode:      linkMode,
+		LinkStyle:     linkStyle,
+		Tracked:       manifest.GetTrackedPolicy(),
+		Companions:    companions,
+		Prompt:        promptSaveConflict,
 	})
 
+	// Later runs find companions with unmanaged names through the manifest
+	for _, result := range results {
+		for _, overlay := range overlays {
+			if result.Success && result.RepoRelativePath == overlay.CompanionPath {
+				manifest.SetOverlay(overlay.TrackedPath, overlay.CompanionPath)
+			}
+		}
+	}
+
+	// Git would otherwise see the committed file replaced by a symlink
+	if manifest.GetTrackedPolicy() == storage.TrackedSkipWorktree {
+		for i, result := range results {
+			if result.Success && claudeFiles[i].Tracked {
+				if err := repo.SetSkipWorktree(filepath.ToSlash(result.RepoRelativePath), true); err != nil {
+					currentOutput.PrintError("Error: %s: %v", result.RepoRelativePath, err)
+				}
+			}
+		}
+	}
+
+	// The first save creates storage, after loadRepoContext had nowhere to record the clone
+	manifest.AddClone(files.CanonicalPath(repo.RootPath))
+	if err := converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
 	var saved, skipped, errors int
 	for _, result := range results {
 		if result.Success {
 			saved++
-			currentOutput.PrintSuccess("Saved: %s", result.RepoRelativePath)
+			switch {
+			case result.Updated:
+				currentOutput.PrintSuccess("Updated: %s", result.RepoRelativePath)
+			case result.Resolution == operations.SaveConflictOverwrite:
+				currentOutput.PrintSuccess("Saved: %s (replaced stored copy, backup at %s)",
+					result.RepoRelativePath, result.BackupPath)
+			case result.Resolution == operations.SaveConflictKeepBoth:
+				currentOutput.PrintSuccess("Saved: %s (kept stored copy, repo version stored at %s)",
+					result.RepoRelativePath, result.BackupPath)
+			case result.Resolution == operations.SaveConflictMerge:
+				currentOutput.PrintSuccess("Merged: %s", result.RepoRelativePath)
+			default:
+				currentOutput.PrintSuccess("Saved: %s", result.RepoRelativePath)
+			}
+			if result.Warning != "" {
+				currentOutput.PrintInfo("Warning: %s", result.Warning)
+			}
 		} else if result.Skipped {
 			skipped++
 			if result.Warning != "" {
@@ -102,3 +215,11 @@ func runSave(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// promptSaveConflict asks how to handle a file that already exists in storage
+func promptSaveConflict(file files.ClaudeFile, storagePath string) (operations.SaveConflictPolicy, error) {
+	choice, err := promptChoice(
+		fmt.Sprintf("%s already exists in storage at %s. Resolve with", file.RepoRelativePath, storagePath),
+		[]string{"skip", "overwrite", "keep-both", "merge"})
+	return operations.SaveConflictPolicy(choice), err
+}
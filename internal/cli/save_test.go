@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kapetan-io/claude-md.go/internal/cli"
 	"github.com/stretchr/testify/assert"
@@ -114,3 +116,287 @@ func TestSaveCommandErrorCases(t *testing.T) {
 	require.Equal(t, 1, exitCode)
 	assert.Contains(t, stderr.String(), "Error")
 }
+
+func TestSaveCommandOnConflict(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	storageFile := filepath.Join(storageDir, "CLAUDE.md")
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+
+	// setup saves base, edits storage to stored and leaves repo as a regular file holding repo
+	setup := func(t *testing.T, base, stored, repo string) {
+		_ = os.RemoveAll(storageDir)
+		_ = os.Remove(claudeFile)
+		require.NoError(t, os.WriteFile(claudeFile, []byte(base), 0644))
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+		require.NoError(t, os.WriteFile(storageFile, []byte(stored), 0644))
+		require.NoError(t, os.Remove(claudeFile))
+		require.NoError(t, os.WriteFile(claudeFile, []byte(repo), 0644))
+	}
+
+	t.Run("SkipByDefault", func(t *testing.T) {
+		setup(t, "base\n", "stored\n", "repo\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "storage file already exists")
+		assert.Contains(t, stdout.String(), "Summary: 0 saved, 1 skipped, 0 errors")
+		content, err := os.ReadFile(storageFile)
+		require.NoError(t, err)
+		assert.Equal(t, "stored\n", string(content))
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		setup(t, "base\n", "stored\n", "repo\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"save", "--on-conflict=overwrite"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "replaced stored copy, backup at")
+		content, err := os.ReadFile(claudeFile)
+		require.NoError(t, err)
+		assert.Equal(t, "repo\n", string(content))
+
+		backups, err := os.ReadDir(filepath.Join(storageDir, ".backups"))
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		content, err = os.ReadFile(filepath.Join(storageDir, ".backups", backups[0].Name()))
+		require.NoError(t, err)
+		assert.Equal(t, "stored\n", string(content))
+	})
+
+	t.Run("KeepBoth", func(t *testing.T) {
+		setup(t, "base\n", "stored\n", "repo\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"save", "--on-conflict=keep-both"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "kept stored copy")
+		content, err := os.ReadFile(claudeFile)
+		require.NoError(t, err)
+		assert.Equal(t, "stored\n", string(content))
+
+		// The copy sits with the backups, where restore does not pick it up
+		copies, err := filepath.Glob(storageFile + ".*")
+		require.NoError(t, err)
+		assert.Empty(t, copies)
+		copies, err = filepath.Glob(filepath.Join(storageDir, ".backups", "CLAUDE.md.*"))
+		require.NoError(t, err)
+		require.Len(t, copies, 1)
+		assert.Contains(t, stdout.String(), "repo version stored at "+copies[0])
+		content, err = os.ReadFile(copies[0])
+		require.NoError(t, err)
+		assert.Equal(t, "repo\n", string(content))
+	})
+
+	t.Run("MergeClean", func(t *testing.T) {
+		setup(t, "one\ntwo\nthree\n", "ONE\ntwo\nthree\n", "one\ntwo\nTHREE\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"save", "--on-conflict=merge"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Merged: CLAUDE.md")
+		content, err := os.ReadFile(claudeFile)
+		require.NoError(t, err)
+		assert.Equal(t, "ONE\ntwo\nTHREE\n", string(content))
+	})
+
+	t.Run("MergeConflict", func(t *testing.T) {
+		setup(t, "one\n", "stored\n", "repo\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"save", "--on-conflict=merge"}, cli.RunOptions{Stdout: &stdout})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "merged with 1 conflict(s)")
+		content, err := os.ReadFile(claudeFile)
+		require.NoError(t, err)
+		assert.Equal(t, "<<<<<<< storage\nstored\n=======\nrepo\n>>>>>>> CLAUDE.md\n", string(content))
+	})
+
+	t.Run("Prompt", func(t *testing.T) {
+		setup(t, "base\n", "stored\n", "repo\n")
+
+		var stdout bytes.Buffer
+		exitCode := cli.Run([]string{"save", "--on-conflict=prompt"}, cli.RunOptions{
+			Stdin:  strings.NewReader("bogus\noverwrite\n"),
+			Stdout: &stdout,
+		})
+
+		require.Equal(t, 0, exitCode)
+		assert.Contains(t, stdout.String(), "Please answer one of")
+		content, err := os.ReadFile(storageFile)
+		require.NoError(t, err)
+		assert.Equal(t, "repo\n", string(content))
+	})
+
+	t.Run("InvalidPolicy", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		exitCode := cli.Run([]string{"save", "--on-conflict=bogus"}, cli.RunOptions{Stdout: &stdout, Stderr: &stderr})
+
+		require.Equal(t, 1, exitCode)
+		assert.Contains(t, stderr.String(), "invalid conflict policy")
+	})
+
+	_ = os.RemoveAll(storageDir)
+}
+
+func TestSaveCommandUpdate(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	storageFile := filepath.Join(storageDir, "CLAUDE.md")
+	_ = os.RemoveAll(storageDir)
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("old content"), 0644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+
+	// Simulate an editor saving by renaming a temp file over the symlink
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(storageFile, past, past))
+	tmpFile := filepath.Join(repoDir, ".CLAUDE.md.tmp")
+	require.NoError(t, os.WriteFile(tmpFile, []byte("new content"), 0644))
+	require.NoError(t, os.Rename(tmpFile, claudeFile))
+
+	stdout.Reset()
+	exitCode := cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "use --update to re-absorb it")
+
+	stdout.Reset()
+	exitCode = cli.Run([]string{"save", "--update"}, cli.RunOptions{Stdout: &stdout})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "Updated: CLAUDE.md")
+	assert.Contains(t, stdout.String(), "Summary: 1 saved, 0 skipped, 0 errors")
+
+	info, err := os.Lstat(claudeFile)
+	require.NoError(t, err)
+	assert.NotEqual(t, 0, info.Mode()&os.ModeSymlink)
+
+	content, err := os.ReadFile(storageFile)
+	require.NoError(t, err)
+	assert.Equal(t, "new content", string(content))
+
+	// The replaced version is kept in history
+	versions, err := os.ReadDir(filepath.Join(storageDir, ".history", "CLAUDE.md"))
+	require.NoError(t, err)
+	var found bool
+	for _, v := range versions {
+		data, err := os.ReadFile(filepath.Join(storageDir, ".history", "CLAUDE.md", v.Name()))
+		require.NoError(t, err)
+		found = found || string(data) == "old content"
+	}
+	assert.True(t, found, "old content should be recorded in history")
+
+	_ = os.RemoveAll(storageDir)
+}
+
+func TestSaveCommandChangesLinkMode(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	storageFile := filepath.Join(storageDir, "CLAUDE.md")
+	_ = os.RemoveAll(storageDir)
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("content"), 0644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save", "--link-mode=copy"}, cli.RunOptions{Stdout: &stdout}))
+	info, err := os.Lstat(claudeFile)
+	require.NoError(t, err)
+	assert.True(t, info.Mode().IsRegular())
+
+	// Without a link mode the copy is left alone
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Summary: 0 saved, 1 skipped, 0 errors")
+
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"save", "--link-mode=symlink"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Saved: CLAUDE.md")
+	target, err := os.Readlink(claudeFile)
+	require.NoError(t, err)
+	assert.Equal(t, storageFile, target)
+
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"status"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "linked       CLAUDE.md")
+
+	_ = os.RemoveAll(storageDir)
+}
+
+func TestSaveCommandPreservesMetadata(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("private"), 0600))
+	mtime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(claudeFile, mtime, mtime))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+
+	assertMeta := func(path string) {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), path)
+		assert.True(t, info.ModTime().Equal(mtime), path)
+	}
+	assertMeta(filepath.Join(storageDir, "CLAUDE.md"))
+
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"eject", "CLAUDE.md"}, cli.RunOptions{Stdout: &stdout}))
+	assertMeta(claudeFile)
+
+	_ = os.RemoveAll(storageDir)
+}
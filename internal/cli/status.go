@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of CLAUDE.md files",
+	Long: `Lists every stored CLAUDE.md file and every unsaved CLAUDE.md in the repository
+with its state:
+  linked        Linked to storage (or an up to date hardlink or copy)
+  missing       Stored but not in the working tree, run restore
+  moved         The link now lives at another path, run the suggested mv
+  modified      A hardlink or copy that differs from storage, run sync
+  conflict      A regular file where the link should be
+  wrong target  A symlink that points somewhere other than storage
+  unsaved       A CLAUDE.md that is not in storage, run save
+  tracked       A CLAUDE.md that git tracks, left alone (see 'claude-md init --tracked')
+  ejected       Ejected with 'claude-md eject', left alone until saved or restored by name
+
+With 'init --tracked=local' each tracked file is shown with its personal
+overlay, the companion file claude-md manages next to it.
+
+--all-worktrees lists the files of every worktree listed by 'git worktree list',
+each under its path and branch.`,
+	Example: `  # Show the state of all CLAUDE.md files
+  claude-md status
+
+  # Show every worktree sharing this repository's storage
+  claude-md status --all-worktrees`,
+	RunE: runStatus,
+}
+
+var statusAllWorktrees bool
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	addRecurseSubmodulesFlag(statusCmd)
+	statusCmd.Flags().BoolVar(&statusAllWorktrees, "all-worktrees", false,
+		"show every worktree of the repository, which all share its storage")
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	ctx, err := readRepoContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	if !statusAllWorktrees {
+		return printStatus(ctx)
+	}
+	failed, err := forEachWorktree(ctx, printStatus)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("status failed in %d worktrees", failed)
+	}
+	return nil
+}
+
+// printStatus lists the state of every managed file in the working tree of ctx
+func printStatus(ctx *repoContext) error {
+	repo, converter := ctx.Repo, ctx.Converter
+
+	manifest, err := converter.LoadManifest()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	claudeFiles, err := findManagedFiles(ctx)
+	if err != nil {
+		currentOutput.PrintError("Error finding CLAUDE.md files: %v", err)
+		return err
+	}
+
+	storedFiles, err := files.FindStoredFiles(converter.GetRepoStorageDir(), converter)
+	if err != nil {
+		currentOutput.PrintError("Error finding stored files: %v", err)
+		return err
+	}
+
+	overlays, err := findOverlays(ctx, manifest, claudeFiles)
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	claudeFiles = append(claudeFiles, operations.CompanionFiles(overlays, claudeFiles, repo.RootPath)...)
+
+	statuses := operations.Status(claudeFiles, storedFiles, operations.StatusOptions{
+		RepoRoot: repo.RootPath,
+		Manifest: manifest,
+	})
+
+	if len(statuses) == 0 {
+		currentOutput.PrintInfo("No CLAUDE.md files found")
+		return nil
+	}
+
+	// Companions are listed under the tracked file they overlay
+	companionOf := make(map[string]string, len(overlays))
+	isCompanion := make(map[string]bool, len(overlays))
+	for _, overlay := range overlays {
+		companionOf[overlay.TrackedPath] = overlay.CompanionPath
+		isCompanion[overlay.CompanionPath] = true
+	}
+	states := make(map[string]operations.FileState, len(statuses))
+	for _, status := range statuses {
+		states[status.RepoRelativePath] = status.State
+	}
+
+	for _, status := range statuses {
+		if isCompanion[status.RepoRelativePath] {
+			continue
+		}
+		if companion, ok := companionOf[status.RepoRelativePath]; ok {
+			currentOutput.PrintInfo("%-12s %s", status.State, status.RepoRelativePath)
+			if state, ok := states[companion]; ok {
+				currentOutput.PrintInfo("  overlay    %s (%s)", companion, state)
+			} else {
+				currentOutput.PrintInfo("  overlay    %s (not created, add personal instructions there and run save)",
+					companion)
+			}
+			continue
+		}
+		if status.State == operations.StateMoved {
+			currentOutput.PrintInfo("%-12s %s -> %s (run 'claude-md mv %s %s')", status.State,
+				status.RepoRelativePath, status.MovedTo, status.RepoRelativePath, status.MovedTo)
+			continue
+		}
+		currentOutput.PrintInfo("%-12s %s", status.State, status.RepoRelativePath)
+	}
+
+	return nil
+}
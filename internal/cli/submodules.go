@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// recurseSubmodules is set by --recurse-submodules on the commands that take it
+var recurseSubmodules bool
+
+// addRecurseSubmodulesFlag registers --recurse-submodules on cmd. With the flag,
+// cmd runs in the repository and then again inside every checked out submodule,
+// so each submodule's files are handled under its own origin and storage, as
+// if the command had been run there.
+func addRecurseSubmodulesFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&recurseSubmodules, "recurse-submodules", false,
+		"also run in every checked out submodule, each under its own repository")
+
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if !recurseSubmodules {
+			return run(cmd, args)
+		}
+		if len(args) > 0 {
+			err := errors.New("--recurse-submodules cannot be combined with paths")
+			currentOutput.PrintError("Error: %v", err)
+			return err
+		}
+		return runInSubmodules(cmd, run)
+	}
+}
+
+// runInSubmodules runs run in the repository of the current directory, then in
+// each of its checked out submodules, parents before their own submodules
+func runInSubmodules(cmd *cobra.Command, run func(*cobra.Command, []string) error) error {
+	repo, err := git.FindRepository()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	submodules, err := repo.Submodules()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	defer func() { _ = os.Chdir(dir) }()
+
+	var failed int
+	if err := run(cmd, nil); err != nil {
+		failed++
+	}
+	for _, path := range submodules {
+		currentOutput.PrintInfo("\nSubmodule: %s", path)
+		if err := os.Chdir(filepath.Join(repo.RootPath, filepath.FromSlash(path))); err != nil {
+			failed++
+			currentOutput.PrintError("Error: %v", err)
+			continue
+		}
+		if err := run(cmd, nil); err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed in %d repositories", failed)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmodules(t *testing.T) {
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	libStorageDir := filepath.Join(home, ".claude", "claude-md", "test", "lib.git")
+	for _, dir := range []string{storageDir, libStorageDir} {
+		_ = os.RemoveAll(dir)
+		defer func(dir string) { _ = os.RemoveAll(dir) }(dir)
+	}
+
+	git := func(dir string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	// The library lives in its own repository and is checked out at lib/
+	libSource := cli.SetupTestGitRepo(t)
+	git(libSource, "commit", "--allow-empty", "-m", "Initial commit")
+	repoDir := cli.SetupTestGitRepo(t)
+	git(repoDir, "-c", "protocol.file.allow=always", "submodule", "add", libSource, "lib")
+	libDir := filepath.Join(repoDir, "lib")
+	git(libDir, "remote", "set-url", "origin", "https://github.com/test/lib.git")
+	git(libDir, "config", "user.email", "test@example.com")
+	require.NoError(t, os.Chdir(repoDir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "CLAUDE.md"), []byte("app"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(libDir, "CLAUDE.md"), []byte("lib"), 0644))
+
+	t.Run("StopsAtSubmodule", func(t *testing.T) {
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Saved: CLAUDE.md")
+		assert.NotContains(t, stdout.String(), "lib/CLAUDE.md")
+
+		info, err := os.Lstat(filepath.Join(libDir, "CLAUDE.md"))
+		require.NoError(t, err)
+		assert.True(t, info.Mode().IsRegular())
+
+		require.NoError(t, os.MkdirAll(filepath.Join(libDir, "docs"), 0755))
+		var stderr bytes.Buffer
+		assert.Equal(t, 1, cli.Run([]string{"add", "lib/docs"}, cli.RunOptions{Stdout: &stdout, Stderr: &stderr}))
+		assert.Contains(t, stderr.String(), "lib/docs/CLAUDE.md is inside the repository at lib")
+	})
+
+	t.Run("RecurseSubmodules", func(t *testing.T) {
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"save", "--recurse-submodules"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "\nSubmodule: lib\n")
+
+		// The submodule's file is stored under its own repository
+		target, err := os.Readlink(filepath.Join(libDir, "CLAUDE.md"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(libStorageDir, "CLAUDE.md"), target)
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"status", "--recurse-submodules"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Equal(t, "linked       CLAUDE.md\n\nSubmodule: lib\nlinked       CLAUDE.md\n", stdout.String())
+
+		require.NoError(t, os.Remove(filepath.Join(libDir, "CLAUDE.md")))
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"restore", "--recurse-submodules"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Submodule: lib\nRestored: CLAUDE.md\n")
+
+		var stderr bytes.Buffer
+		assert.Equal(t, 1, cli.Run([]string{"restore", "--recurse-submodules", "CLAUDE.md"},
+			cli.RunOptions{Stdout: &stdout, Stderr: &stderr}))
+		assert.Contains(t, stderr.String(), "--recurse-submodules cannot be combined with paths")
+	})
+}
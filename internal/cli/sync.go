@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Re-absorb edited CLAUDE.md files into storage",
+	Long: `Brings the working tree and storage back in step.
+
+For symlinked files this is the same as 'claude-md save --update': regular files that
+replaced their symlink are copied back into storage when they are newer than (or
+identical to) their stored copy, and linked again. New CLAUDE.md files are saved as usual.
+
+For files placed as hardlinks or copies, sync compares both sides with the content
+hash recorded at the last sync:
+- Edited in the working tree only: the edit is pushed into storage
+- Edited in storage only: the working tree copy is refreshed
+- Edited on both sides: reported as a conflict and left alone
+
+The previous stored version is always kept in history.`,
+	Example: `  # Pick up edits made by tools that replaced the symlink or edited a copy
+  claude-md sync`,
+	RunE: runSync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	saveUpdate = true
+	if err := runSave(cmd, args); err != nil {
+		return err
+	}
+
+	ctx, err := loadRepoContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+	repo, converter := ctx.Repo, ctx.Converter
+
+	manifest, err := converter.LoadManifest()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	storedFiles, err := files.FindStoredFiles(converter.GetRepoStorageDir(), converter)
+	if err != nil {
+		currentOutput.PrintError("Error finding stored files: %v", err)
+		return err
+	}
+
+	results := operations.SyncCopies(storedFiles, operations.SyncOptions{
+		RepoRoot:      repo.RootPath,
+		PathConverter: converter,
+		Manifest:      manifest,
+	})
+	if len(results) == 0 {
+		return nil
+	}
+
+	if err := converter.SaveManifest(manifest); err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	var pushed, pulled, conflicts, errors int
+	for _, result := range results {
+		switch {
+		case result.Error != nil:
+			errors++
+			currentOutput.PrintError("Error: %s: %v", result.RepoRelativePath, result.Error)
+		case result.Action == operations.SyncPushed:
+			pushed++
+			currentOutput.PrintSuccess("Pushed: %s (working tree -> storage)", result.RepoRelativePath)
+		case result.Action == operations.SyncPulled:
+			pulled++
+			currentOutput.PrintSuccess("Pulled: %s (storage -> working tree)", result.RepoRelativePath)
+		case result.Action == operations.SyncConflict:
+			conflicts++
+			currentOutput.PrintInfo("Conflict: %s", result.Warning)
+		}
+	}
+
+	currentOutput.PrintInfo("\nCopies: %d pushed, %d pulled, %d conflicts, %d errors", pushed, pulled, conflicts, errors)
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncCommand(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("content"), 0644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+
+	// A tool wrote the same content back as a regular file
+	require.NoError(t, os.Remove(claudeFile))
+	require.NoError(t, os.WriteFile(claudeFile, []byte("content"), 0644))
+
+	stdout.Reset()
+	exitCode := cli.Run([]string{"sync"}, cli.RunOptions{Stdout: &stdout})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "Updated: CLAUDE.md")
+
+	info, err := os.Lstat(claudeFile)
+	require.NoError(t, err)
+	assert.NotEqual(t, 0, info.Mode()&os.ModeSymlink)
+
+	_ = os.RemoveAll(storageDir)
+}
+
+func TestSyncCommandCopyMode(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	err = os.Chdir(repoDir)
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	storageFile := filepath.Join(storageDir, "CLAUDE.md")
+	_ = os.RemoveAll(storageDir)
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"init", "--link-mode=copy"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Link mode: copy")
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("v1"), 0644))
+
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Saved: CLAUDE.md")
+
+	// The working tree holds a copy, not a symlink
+	info, err := os.Lstat(claudeFile)
+	require.NoError(t, err)
+	assert.True(t, info.Mode().IsRegular())
+
+	readFile := func(path string) string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	t.Run("PushesWorkingTreeEdits", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(claudeFile, []byte("v2 from repo"), 0644))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"sync"}, cli.RunOptions{Stdout: &stdout}))
+
+		assert.Contains(t, stdout.String(), "Pushed: CLAUDE.md")
+		assert.Equal(t, "v2 from repo", readFile(storageFile))
+	})
+
+	t.Run("PullsStorageEdits", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(storageFile, []byte("v3 from storage"), 0644))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"sync"}, cli.RunOptions{Stdout: &stdout}))
+
+		assert.Contains(t, stdout.String(), "Pulled: CLAUDE.md")
+		assert.Equal(t, "v3 from storage", readFile(claudeFile))
+	})
+
+	t.Run("ReportsErrors", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(storageFile, []byte("v4 from storage"), 0644))
+		// A directory where the new copy is written makes the pull fail
+		blocker := filepath.Join(claudeFile+".claude-md.tmp", "blocked")
+		require.NoError(t, os.MkdirAll(blocker, 0755))
+
+		stdout.Reset()
+		var stderr bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"sync"}, cli.RunOptions{Stdout: &stdout, Stderr: &stderr}))
+		assert.Contains(t, stderr.String(), "Error: CLAUDE.md: failed to update working tree copy: ")
+		assert.Contains(t, stdout.String(), "0 pulled, 0 conflicts, 1 errors")
+
+		require.NoError(t, os.RemoveAll(filepath.Dir(blocker)))
+		require.Equal(t, 0, cli.Run([]string{"sync"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Equal(t, "v4 from storage", readFile(claudeFile))
+	})
+
+	t.Run("FlagsConflicts", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(storageFile, []byte("storage edit"), 0644))
+		require.NoError(t, os.WriteFile(claudeFile, []byte("repo edit"), 0644))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"sync"}, cli.RunOptions{Stdout: &stdout}))
+
+		assert.Contains(t, stdout.String(), "Conflict: CLAUDE.md changed in both")
+		assert.Equal(t, "storage edit", readFile(storageFile))
+		assert.Equal(t, "repo edit", readFile(claudeFile))
+
+		// Clear leaves a modified copy alone
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"clear"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "copy differs from storage")
+		assert.FileExists(t, claudeFile)
+
+		require.NoError(t, os.WriteFile(claudeFile, []byte("storage edit"), 0644))
+	})
+
+	t.Run("ClearAndRestore", func(t *testing.T) {
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"clear"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Removed: CLAUDE.md")
+		assert.NoFileExists(t, claudeFile)
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Restored: CLAUDE.md")
+
+		info, err := os.Lstat(claudeFile)
+		require.NoError(t, err)
+		assert.True(t, info.Mode().IsRegular())
+		assert.Equal(t, "storage edit", readFile(claudeFile))
+
+		// A second restore sees the copy is already in place
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Summary: 0 restored, 1 skipped (0 warnings)")
+	})
+
+	_ = os.RemoveAll(storageDir)
+}
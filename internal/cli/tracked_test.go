@@ -0,0 +1,194 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackedFiles(t *testing.T) {
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+
+	// setup creates a repository that commits a team CLAUDE.md
+	setup := func(t *testing.T) (string, func(args ...string) string) {
+		repoDir := cli.SetupTestGitRepo(t)
+		require.NoError(t, os.Chdir(repoDir))
+		_ = os.RemoveAll(storageDir)
+		t.Cleanup(func() { _ = os.RemoveAll(storageDir) })
+
+		git := func(args ...string) string {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = repoDir
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(output))
+			return string(output)
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, "CLAUDE.md"), []byte("team"), 0644))
+		git("add", "CLAUDE.md")
+		git("commit", "-m", "Add CLAUDE.md")
+		return repoDir, git
+	}
+
+	t.Run("RefusedByDefault", func(t *testing.T) {
+		repoDir, git := setup(t)
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Skipping CLAUDE.md: tracked by git")
+
+		info, err := os.Lstat(filepath.Join(repoDir, "CLAUDE.md"))
+		require.NoError(t, err)
+		assert.True(t, info.Mode().IsRegular())
+		assert.Empty(t, git("status", "--porcelain"))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"status"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "tracked      CLAUDE.md")
+	})
+
+	t.Run("RestoreRefusedByDefault", func(t *testing.T) {
+		repoDir, git := setup(t)
+		require.NoError(t, os.MkdirAll(storageDir, 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(storageDir, "CLAUDE.md"), []byte("personal"), 0644))
+
+		for _, policy := range []string{"replace", "backup", "adopt-identical"} {
+			var stdout bytes.Buffer
+			require.Equal(t, 0, cli.Run([]string{"restore", "--on-conflict=" + policy}, cli.RunOptions{Stdout: &stdout}))
+			assert.Contains(t, stdout.String(), "Skipping CLAUDE.md: tracked by git")
+
+			content, err := os.ReadFile(filepath.Join(repoDir, "CLAUDE.md"))
+			require.NoError(t, err)
+			assert.Equal(t, "team", string(content))
+			assert.Empty(t, git("status", "--porcelain"))
+		}
+
+		// A committed file deleted from the working tree is not replaced either
+		require.NoError(t, os.Remove(filepath.Join(repoDir, "CLAUDE.md")))
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Skipping CLAUDE.md: tracked by git")
+		_, err := os.Lstat(filepath.Join(repoDir, "CLAUDE.md"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("SkipWorktree", func(t *testing.T) {
+		repoDir, git := setup(t)
+		claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+		require.NoError(t, os.WriteFile(claudeFile, []byte("personal"), 0644))
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"init", "--tracked=skip-worktree"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Tracked files: skip-worktree")
+		require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+
+		info, err := os.Lstat(claudeFile)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&os.ModeSymlink)
+		assert.Equal(t, "S CLAUDE.md\n", git("ls-files", "-v", "CLAUDE.md"))
+		assert.Empty(t, git("status", "--porcelain"))
+
+		// Clearing puts the committed file back and lets git see it again
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"clear"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Checked out: CLAUDE.md (committed version)")
+		content, err := os.ReadFile(claudeFile)
+		require.NoError(t, err)
+		assert.Equal(t, "team", string(content))
+		assert.Equal(t, "H CLAUDE.md\n", git("ls-files", "-v", "CLAUDE.md"))
+
+		// Restoring over the committed file marks it again
+		require.Equal(t, 0, cli.Run([]string{"restore", "--on-conflict=replace"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Equal(t, "S CLAUDE.md\n", git("ls-files", "-v", "CLAUDE.md"))
+		content, err = os.ReadFile(claudeFile)
+		require.NoError(t, err)
+		assert.Equal(t, "personal", string(content))
+	})
+
+	t.Run("Local", func(t *testing.T) {
+		repoDir, git := setup(t)
+		localFile := filepath.Join(repoDir, "CLAUDE.local.md")
+		require.NoError(t, os.WriteFile(localFile, []byte("personal"), 0644))
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"init", "--tracked=local"}, cli.RunOptions{Stdout: &stdout}))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Skipping CLAUDE.md: tracked by git, keep personal instructions in CLAUDE.local.md")
+		assert.Contains(t, stdout.String(), "Saved: CLAUDE.local.md")
+
+		info, err := os.Lstat(localFile)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&os.ModeSymlink)
+		_, err = os.Stat(filepath.Join(storageDir, "CLAUDE.local.md"))
+		assert.NoError(t, err)
+		assert.Empty(t, git("status", "--porcelain"))
+	})
+
+	t.Run("OverlayStatus", func(t *testing.T) {
+		repoDir, _ := setup(t)
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"init", "--tracked=local"}, cli.RunOptions{Stdout: &stdout}))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"status"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Equal(t, "tracked      CLAUDE.md\n"+
+			"  overlay    CLAUDE.local.md (not created, add personal instructions there and run save)\n",
+			stdout.String())
+
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, "CLAUDE.local.md"), []byte("personal"), 0644))
+		require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"status"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Equal(t, "tracked      CLAUDE.md\n  overlay    CLAUDE.local.md (linked)\n", stdout.String())
+	})
+
+	t.Run("ImportedOverlay", func(t *testing.T) {
+		repoDir, git := setup(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "docs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, "docs", "team.md"), []byte("team docs"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, "CLAUDE.md"),
+			[]byte("See @docs/team.md\n\n@.claude/personal.md\n"), 0644))
+		git("add", ".")
+		git("commit", "-m", "Import personal instructions")
+
+		personal := filepath.Join(repoDir, ".claude", "personal.md")
+		require.NoError(t, os.MkdirAll(filepath.Dir(personal), 0755))
+		require.NoError(t, os.WriteFile(personal, []byte("personal"), 0644))
+
+		var stdout bytes.Buffer
+		require.Equal(t, 0, cli.Run([]string{"init", "--tracked=local"}, cli.RunOptions{Stdout: &stdout}))
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "keep personal instructions in .claude/personal.md")
+		assert.Contains(t, stdout.String(), "Saved: .claude/personal.md")
+		info, err := os.Lstat(personal)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&os.ModeSymlink)
+
+		// The companion's name matches no pattern, the manifest remembers it
+		require.NoError(t, os.Remove(personal))
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Contains(t, stdout.String(), "Restored: .claude/personal.md")
+
+		stdout.Reset()
+		require.Equal(t, 0, cli.Run([]string{"status"}, cli.RunOptions{Stdout: &stdout}))
+		assert.Equal(t, "tracked      CLAUDE.md\n  overlay    .claude/personal.md (linked)\n", stdout.String())
+	})
+}
@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/kapetan-io/claude-md.go/internal/output"
+	"github.com/kapetan-io/claude-md.go/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep CLAUDE.md files linked while you work",
+	Long: `Watches the working tree and repairs it as files change:
+- Links that disappear (for example deleted by a tool or a git clean) are re-created
+- Regular files that replaced their link, as editors that save by renaming do, are
+  re-absorbed into storage and linked again, as 'claude-md save --update' would
+- New CLAUDE.md files are reported with the command to save them, or saved
+  right away with --save-new
+
+Changes are picked up with inotify on Linux and by scanning the tree every
+--interval elsewhere (or with --poll). Bursts of changes, like a branch switch,
+are handled once the tree has been quiet for --debounce. The repository is only
+locked while a change is handled, so other claude-md commands keep working.
+
+Everything watch does is also appended to a log, .git/claude-md-watch.log by
+default. Stop watching with Ctrl-C or SIGTERM.`,
+	Example: `  # Watch the current repository
+  claude-md watch
+
+  # Save new CLAUDE.md files as soon as they appear
+  claude-md watch --save-new
+
+  # Scan instead of using inotify, for example on a network file system
+  claude-md watch --poll --interval=5s`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+var (
+	watchSaveNew  bool
+	watchPoll     bool
+	watchInterval time.Duration
+	watchDebounce time.Duration
+	watchLog      string
+)
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().BoolVar(&watchSaveNew, "save-new", false,
+		"save new CLAUDE.md files instead of only reporting them")
+	watchCmd.Flags().BoolVar(&watchPoll, "poll", false,
+		"scan the tree for changes even where inotify is available")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", watch.DefaultInterval,
+		"how often to scan the tree when polling")
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", 500*time.Millisecond,
+		"how long the tree must be quiet before changes are handled")
+	watchCmd.Flags().StringVar(&watchLog, "log", "",
+		"file to append the watch log to (default: .git/claude-md-watch.log)")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	ctx, err := loadRepoContext()
+	if err != nil {
+		return err
+	}
+	repo, patterns := ctx.Repo, ctx.Converter.Patterns
+	gitDir, err := repo.GitDir()
+	ctx.Close()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+
+	logPath := watchLog
+	if logPath == "" {
+		logPath = filepath.Join(gitDir, "claude-md-watch.log")
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		err = fmt.Errorf("failed to open log: %w", err)
+		currentOutput.PrintError("Error: %v", err)
+		return err
+	}
+	defer func() { _ = logFile.Close() }()
+
+	// Everything printed while watching is also logged, including what each
+	// pass's loadRepoContext reports
+	log := &timestampWriter{w: logFile}
+	previous := currentOutput
+	currentOutput = output.NewOutput(io.MultiWriter(previous.Stdout, log), io.MultiWriter(previous.Stderr, log))
+	defer func() { currentOutput = previous }()
+
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	watcher, err := watch.New(watch.Options{
+		Root:     repo.RootPath,
+		Match:    func(name string) bool { return files.MatchesPatterns(name, patterns) },
+		Interval: watchInterval,
+		Poll:     watchPoll,
+	})
+	if err != nil {
+		currentOutput.PrintError("Error: failed to watch %s: %v", repo.RootPath, err)
+		return err
+	}
+	batches := watch.Debounce(watcher.Events(), watchDebounce)
+	defer func() {
+		_ = watcher.Close()
+		// Let the watcher and debouncer goroutines finish delivering
+		for range batches {
+		}
+	}()
+
+	how := "polling every " + watchInterval.String()
+	if watch.Native(watcher) {
+		how = "inotify"
+	}
+	currentOutput.PrintInfo("Watching %s (%s, log at %s)", repo.RootPath, how, logPath)
+
+	// Catch up with whatever changed while nobody was watching
+	offered := make(map[string]bool)
+	watchPass(offered)
+
+	for {
+		select {
+		case <-signals.Done():
+			currentOutput.PrintInfo("Stopped watching %s", repo.RootPath)
+			return nil
+		case _, ok := <-batches:
+			if !ok {
+				err := fmt.Errorf("watcher for %s stopped", repo.RootPath)
+				currentOutput.PrintError("Error: %v", err)
+				return err
+			}
+			watchPass(offered)
+		}
+	}
+}
+
+// watchPass reconciles the working tree once, holding the repository locks
+// only for the pass. offered remembers new files already reported, so each
+// is suggested once.
+func watchPass(offered map[string]bool) {
+	ctx, err := loadRepoContext()
+	if err != nil {
+		return
+	}
+	defer ctx.Close()
+	repo, converter := ctx.Repo, ctx.Converter
+
+	manifest, err := converter.LoadManifest()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return
+	}
+
+	claudeFiles, err := findManagedFiles(ctx)
+	if err != nil {
+		currentOutput.PrintError("Error finding CLAUDE.md files: %v", err)
+		return
+	}
+
+	storedFiles, err := files.FindStoredFiles(converter.GetRepoStorageDir(), converter)
+	if err != nil {
+		currentOutput.PrintError("Error finding stored files: %v", err)
+		return
+	}
+
+	tracked, err := repo.TrackedPaths()
+	if err != nil {
+		currentOutput.PrintError("Error: %v", err)
+		return
+	}
+
+	result := operations.Reconcile(claudeFiles, storedFiles, operations.ReconcileOptions{
+		RepoRoot:        repo.RootPath,
+		PathConverter:   converter,
+		Manifest:        manifest,
+		SaveNew:         watchSaveNew,
+		Tracked:         manifest.GetTrackedPolicy(),
+		TrackedPaths:    tracked,
+		SetSkipWorktree: repo.SetSkipWorktree,
+	})
+
+	if len(result.Restored) > 0 || len(result.Saved) > 0 {
+		defer syncExcludes(ctx)
+		manifest.AddClone(files.CanonicalPath(repo.RootPath))
+		if err := converter.SaveManifest(manifest); err != nil {
+			currentOutput.PrintError("Error: %v", err)
+			return
+		}
+	}
+
+	for _, restored := range result.Restored {
+		if restored.Success {
+			currentOutput.PrintSuccess("Restored: %s", restored.RepoRelativePath)
+		} else if restored.Warning != "" {
+			currentOutput.PrintInfo("Warning: %s", restored.Warning)
+		}
+	}
+	for _, saved := range result.Saved {
+		switch {
+		case saved.Error != nil:
+			currentOutput.PrintError("Error: %s", saved.Warning)
+		case saved.Updated:
+			currentOutput.PrintSuccess("Updated: %s", saved.RepoRelativePath)
+		case saved.Success:
+			currentOutput.PrintSuccess("Saved: %s", saved.RepoRelativePath)
+		}
+	}
+
+	unsaved := make(map[string]bool, len(result.Unsaved))
+	for _, path := range result.Unsaved {
+		unsaved[path] = true
+		if !offered[path] {
+			offered[path] = true
+			currentOutput.PrintInfo("New file: %s (run 'claude-md save %s')", path, path)
+		}
+	}
+	// Offer again if a file is deleted and comes back
+	for path := range offered {
+		if !unsaved[path] {
+			delete(offered, path)
+		}
+	}
+}
+
+// timestampWriter prefixes each write, one printed line, with the time
+type timestampWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (t *timestampWriter) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, err := fmt.Fprintf(t.w, "%s %s", time.Now().Format(time.RFC3339), p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,107 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer that can be read while a command writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchCommand(t *testing.T) {
+	repoDir := cli.SetupTestGitRepo(t)
+
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+	require.NoError(t, os.Chdir(repoDir))
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+	defer func() { _ = os.RemoveAll(storageDir) }()
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("content"), 0644))
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &bytes.Buffer{}}))
+
+	for _, test := range []struct {
+		name string
+		args []string
+	}{
+		{name: "Native", args: []string{"watch", "--debounce=50ms"}},
+		{name: "Poll", args: []string{"watch", "--poll", "--interval=50ms", "--debounce=50ms"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			logPath := filepath.Join(t.TempDir(), "watch.log")
+			var stdout syncBuffer
+			done := make(chan int)
+			go func() {
+				done <- cli.Run(append(test.args, "--log="+logPath),
+					cli.RunOptions{Stdout: &stdout, Stderr: &stdout})
+			}()
+
+			waitForOutput := func(text string) {
+				t.Helper()
+				require.Eventually(t, func() bool {
+					return strings.Contains(stdout.String(), text)
+				}, 5*time.Second, 10*time.Millisecond, "output so far:\n%s", stdout.String())
+			}
+			waitForOutput("Watching")
+
+			// A deleted link comes back
+			require.NoError(t, os.Remove(claudeFile))
+			waitForOutput("Restored: CLAUDE.md")
+			info, err := os.Lstat(claudeFile)
+			require.NoError(t, err)
+			assert.NotZero(t, info.Mode()&os.ModeSymlink)
+
+			// A new file is offered for saving
+			subFile := filepath.Join(repoDir, "sub", "CLAUDE.md")
+			require.NoError(t, os.MkdirAll(filepath.Dir(subFile), 0755))
+			require.NoError(t, os.WriteFile(subFile, []byte("new"), 0644))
+			waitForOutput("New file: sub/CLAUDE.md (run 'claude-md save sub/CLAUDE.md')")
+			require.NoError(t, os.RemoveAll(filepath.Dir(subFile)))
+
+			require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+			select {
+			case code := <-done:
+				assert.Equal(t, 0, code)
+			case <-time.After(5 * time.Second):
+				t.Fatal("watch did not stop on SIGINT")
+			}
+			assert.Contains(t, stdout.String(), "Stopped watching")
+
+			log, err := os.ReadFile(logPath)
+			require.NoError(t, err)
+			assert.Contains(t, string(log), "Restored: CLAUDE.md")
+			assert.Contains(t, string(log), "Stopped watching")
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllWorktrees(t *testing.T) {
+	oldDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(oldDir) }()
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+	defer func() { _ = os.RemoveAll(storageDir) }()
+
+	repoDir := cli.SetupTestGitRepo(t)
+	require.NoError(t, os.Chdir(repoDir))
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	git("commit", "--allow-empty", "-m", "Initial commit")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "CLAUDE.md"), []byte("personal"), 0644))
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &bytes.Buffer{}}))
+
+	worktreeDir := filepath.Join(t.TempDir(), "feature")
+	git("worktree", "add", "-b", "feature", worktreeDir)
+	goneDir := filepath.Join(t.TempDir(), "gone")
+	git("worktree", "add", "--detach", goneDir)
+	require.NoError(t, os.RemoveAll(goneDir))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, cli.Run([]string{"status", "--all-worktrees"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Worktree: "+repoDir+" (")
+	assert.Contains(t, stdout.String(), "Worktree: "+worktreeDir+" (feature)\nmissing      CLAUDE.md\n")
+	assert.Contains(t, stdout.String(), "Warning: Skipping worktree "+goneDir)
+
+	stdout.Reset()
+	require.Equal(t, 0, cli.Run([]string{"restore", "--all-worktrees"}, cli.RunOptions{Stdout: &stdout}))
+	assert.Contains(t, stdout.String(), "Worktree: "+worktreeDir+" (feature)\nRestored: CLAUDE.md\n")
+
+	target, err := os.Readlink(filepath.Join(worktreeDir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(storageDir, "CLAUDE.md"), target)
+
+	// The worktree is recorded so commands like forget find its links
+	manifest, err := os.ReadFile(filepath.Join(storageDir, ".manifest.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), worktreeDir)
+
+	// The shared exclude file keeps listing files linked only in another worktree
+	require.NoError(t, os.Chdir(worktreeDir))
+	require.NoError(t, os.MkdirAll(filepath.Join(worktreeDir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "docs", "CLAUDE.md"), []byte("docs"), 0644))
+	require.Equal(t, 0, cli.Run([]string{"save"}, cli.RunOptions{Stdout: &bytes.Buffer{}}))
+	require.NoError(t, os.Chdir(repoDir))
+	require.Equal(t, 0, cli.Run([]string{"restore"}, cli.RunOptions{Stdout: &bytes.Buffer{}}))
+
+	exclude, err := os.ReadFile(filepath.Join(repoDir, ".git", "info", "exclude"))
+	require.NoError(t, err)
+	assert.Contains(t, string(exclude), "/CLAUDE.md\n/docs/CLAUDE.md\n")
+}
@@ -11,10 +11,19 @@ type ClaudeFile struct {
 	AbsolutePath     string // Full path to file
 	RepoRelativePath string // Path relative to repo root
 	IsSymlink        bool   // Whether it's already a symlink
+	Tracked          bool   // Whether git tracks it, set by callers that read the index
 }
 
 // FindClaudeFiles finds all CLAUDE.md files in the repository
 func FindClaudeFiles(repoRoot string) ([]ClaudeFile, error) {
+	return FindManagedFiles(repoRoot, nil)
+}
+
+// FindManagedFiles finds all files in the repository whose name matches one of
+// patterns, or CLAUDE.md when patterns is empty. Submodules, nested repositories
+// and worktrees inside the repository belong to another repository and are
+// not searched.
+func FindManagedFiles(repoRoot string, patterns []string) ([]ClaudeFile, error) {
 	var claudeFiles []ClaudeFile
 
 	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
@@ -26,9 +35,12 @@ func FindClaudeFiles(repoRoot string) ([]ClaudeFile, error) {
 		if info.IsDir() && info.Name() == ".git" {
 			return filepath.SkipDir
 		}
+		if info.IsDir() && path != repoRoot && IsRepositoryRoot(path) {
+			return filepath.SkipDir
+		}
 
-		// Check if filename matches CLAUDE.md (case-insensitive)
-		if !info.IsDir() && strings.EqualFold(filepath.Base(path), "CLAUDE.md") {
+		// Check if filename matches a managed pattern (case-insensitive)
+		if !info.IsDir() && MatchesPatterns(filepath.Base(path), patterns) {
 			relPath, err := filepath.Rel(repoRoot, path)
 			if err != nil {
 				return err
@@ -52,6 +64,13 @@ func FindClaudeFiles(repoRoot string) ([]ClaudeFile, error) {
 	return claudeFiles, err
 }
 
+// IsRepositoryRoot reports whether dir is the top of a git working tree, with a
+// .git directory, or a .git file as submodules and worktrees have
+func IsRepositoryRoot(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
 // IsSymlink checks if a path is a symbolic link
 func IsSymlink(path string) (bool, error) {
 	info, err := os.Lstat(path)
@@ -60,3 +79,43 @@ func IsSymlink(path string) (bool, error) {
 	}
 	return info.Mode()&os.ModeSymlink != 0, nil
 }
+
+// ReadLinkTarget returns the absolute path a symlink points at.
+// Relative targets are resolved against the directory the link lives in.
+func ReadLinkTarget(linkPath string) (string, error) {
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(target) {
+		return filepath.Clean(target), nil
+	}
+
+	absLinkPath, err := filepath.Abs(linkPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(CanonicalPath(filepath.Dir(absLinkPath)), target), nil
+}
+
+// CanonicalPath returns path with all symlinks resolved so two paths to the
+// same file compare equal. Paths that do not exist are only made absolute and cleaned.
+func CanonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// IsWithin reports whether path lies inside dir, comparing whole path components
+// after resolving symlinks, so "/s/api-gateway/x" is not within "/s/api"
+func IsWithin(path, dir string) bool {
+	rel, err := filepath.Rel(CanonicalPath(dir), CanonicalPath(path))
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,130 @@
+package files
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Filter narrows an operation to some of the repository's files
+// The zero Filter matches every file
+type Filter struct {
+	Paths   []string // Repo relative files or directories, "." for the whole repo
+	Include []string // Globs a file must match, "**" matches any number of directories
+	Exclude []string // Globs a file must not match
+}
+
+// NewFilter validates the globs and returns a Filter
+func NewFilter(paths, include, exclude []string) (Filter, error) {
+	for _, glob := range append(append([]string{}, include...), exclude...) {
+		for _, segment := range strings.Split(glob, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return Filter{}, fmt.Errorf("invalid glob %q: %w", glob, err)
+			}
+		}
+	}
+	return Filter{Paths: paths, Include: include, Exclude: exclude}, nil
+}
+
+// Match reports whether the repo relative path passes the filter
+func (f Filter) Match(repoRelativePath string) bool {
+	p := filepath.ToSlash(repoRelativePath)
+
+	if len(f.Paths) > 0 {
+		found := false
+		for _, dir := range f.Paths {
+			if UnderPath(p, dir) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Include) > 0 {
+		found := false
+		for _, glob := range f.Include {
+			if matchGlob(glob, p) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, glob := range f.Exclude {
+		if matchGlob(glob, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClaudeFiles returns the files that pass the filter
+func (f Filter) ClaudeFiles(claudeFiles []ClaudeFile) []ClaudeFile {
+	var matched []ClaudeFile
+	for _, file := range claudeFiles {
+		if f.Match(file.RepoRelativePath) {
+			matched = append(matched, file)
+		}
+	}
+	return matched
+}
+
+// StoredFiles returns the stored files that pass the filter
+func (f Filter) StoredFiles(storedFiles []StoredFile) []StoredFile {
+	var matched []StoredFile
+	for _, stored := range storedFiles {
+		if f.Match(stored.RepoRelativePath) {
+			matched = append(matched, stored)
+		}
+	}
+	return matched
+}
+
+// UnderPath reports whether the slash separated repo relative path p is dir or lies below it
+func UnderPath(p, dir string) bool {
+	dir = strings.TrimSuffix(filepath.ToSlash(dir), "/")
+	return dir == "." || dir == "" || p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// matchGlob reports whether p or one of its parent directories matches glob,
+// so "services/payments" and "services/payments/**" select the same files
+func matchGlob(glob, p string) bool {
+	patternParts := strings.Split(strings.Trim(glob, "/"), "/")
+	pathParts := strings.Split(p, "/")
+	for n := len(pathParts); n > 0; n-- {
+		if matchSegments(patternParts, pathParts[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments where "**"
+// stands for zero or more segments
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
@@ -0,0 +1,46 @@
+package files_test
+
+import (
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterMatch(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		paths    []string
+		include  []string
+		exclude  []string
+		path     string
+		expected bool
+	}{
+		{name: "EmptyMatchesAll", path: "a/b/CLAUDE.md", expected: true},
+		{name: "PathFile", paths: []string{"CLAUDE.md"}, path: "CLAUDE.md", expected: true},
+		{name: "PathFileNotNested", paths: []string{"CLAUDE.md"}, path: "docs/CLAUDE.md", expected: false},
+		{name: "PathDirectory", paths: []string{"services"}, path: "services/api/CLAUDE.md", expected: true},
+		{name: "PathDirectoryPrefixOnly", paths: []string{"services"}, path: "services-old/CLAUDE.md", expected: false},
+		{name: "PathRoot", paths: []string{"."}, path: "docs/CLAUDE.md", expected: true},
+		{name: "IncludeDoubleStar", include: []string{"services/payments/**"}, path: "services/payments/api/CLAUDE.md", expected: true},
+		{name: "IncludeDoubleStarOther", include: []string{"services/payments/**"}, path: "services/orders/CLAUDE.md", expected: false},
+		{name: "IncludeLeadingDoubleStar", include: []string{"**/api/CLAUDE.md"}, path: "a/b/api/CLAUDE.md", expected: true},
+		{name: "IncludeDirectory", include: []string{"services/*"}, path: "services/api/CLAUDE.md", expected: true},
+		{name: "IncludeSingleStarOneLevel", include: []string{"*/CLAUDE.md"}, path: "a/b/CLAUDE.md", expected: false},
+		{name: "Exclude", exclude: []string{"vendor/**"}, path: "vendor/x/CLAUDE.md", expected: false},
+		{name: "ExcludeWins", include: []string{"**"}, exclude: []string{"docs"}, path: "docs/CLAUDE.md", expected: false},
+		{name: "ExcludeOther", exclude: []string{"docs"}, path: "CLAUDE.md", expected: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := files.NewFilter(test.paths, test.include, test.exclude)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, filter.Match(test.path))
+		})
+	}
+}
+
+func TestNewFilterInvalidGlob(t *testing.T) {
+	_, err := files.NewFilter(nil, []string{"services/[a"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package files
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultPatterns are the file names managed when a repository configures none
+var DefaultPatterns = []string{"CLAUDE.md"}
+
+// MatchesPatterns reports whether a file name matches one of the glob patterns,
+// ignoring case. No patterns means DefaultPatterns.
+func MatchesPatterns(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		patterns = DefaultPatterns
+	}
+	for _, pattern := range patterns {
+		// A wildcard never picks up hidden files such as the storage manifest
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(pattern, ".") {
+			continue
+		}
+		if ok, _ := filepath.Match(strings.ToLower(pattern), strings.ToLower(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalName returns the name of the personal companion of a managed file,
+// CLAUDE.local.md for CLAUDE.md
+func LocalName(name string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + ".local" + ext
+}
+
+// WithLocalPatterns returns patterns, or DefaultPatterns when empty, followed
+// by the pattern of each one's personal companion
+func WithLocalPatterns(patterns []string) []string {
+	if len(patterns) == 0 {
+		patterns = DefaultPatterns
+	}
+	result := append([]string{}, patterns...)
+	for _, pattern := range patterns {
+		result = append(result, LocalName(pattern))
+	}
+	return result
+}
+
+// ValidatePattern checks that pattern is a usable file name glob
+func ValidatePattern(pattern string) error {
+	if pattern == "" || strings.ContainsAny(pattern, "/~") {
+		return fmt.Errorf("invalid file pattern %q (must be a file name without / or ~)", pattern)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+	}
+	return nil
+}
@@ -14,7 +14,7 @@ type StoredFile struct {
 	StoragePath      string // Full path to stored file
 }
 
-// FindStoredFiles finds all stored CLAUDE.md files for a repository
+// FindStoredFiles finds all stored files for a repository that match its configured patterns
 func FindStoredFiles(repoStorageDir string, converter *storage.PathConverter) ([]StoredFile, error) {
 	entries, err := os.ReadDir(repoStorageDir)
 	if err != nil {
@@ -32,9 +32,9 @@ func FindStoredFiles(repoStorageDir string, converter *storage.PathConverter) ([
 
 		filename := entry.Name()
 
-		// Only include files that match CLAUDE.md (case-insensitive)
-		// They should end with CLAUDE.md or be exactly CLAUDE.md
-		if !strings.EqualFold(filename, "CLAUDE.md") && !strings.HasSuffix(strings.ToLower(filename), "~claude.md") {
+		// Only include files whose name in the repo matches a managed pattern (case-insensitive)
+		parts := strings.Split(filename, "~")
+		if !MatchesPatterns(parts[len(parts)-1], converter.Patterns) {
 			continue
 		}
 
@@ -1,11 +1,15 @@
 package git
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -14,31 +18,75 @@ type Repository struct {
 	RootPath  string
 	RepoName  string
 	UserEmail string
+	CommonDir string // Absolute git directory shared by all worktrees
 }
 
-// FindRepository detects the git repository from the current or parent directories
+// FindRepository detects the repository of the current directory the way git
+// does, honoring GIT_DIR and GIT_WORK_TREE. Run from inside a git directory, it
+// finds the working tree that directory belongs to.
 func FindRepository() (*Repository, error) {
-	dir, err := os.Getwd()
+	// Commands run in the working tree, so relative paths git was given must
+	// be made absolute first
+	for _, name := range []string{"GIT_DIR", "GIT_WORK_TREE"} {
+		if value := os.Getenv(name); value != "" && !filepath.IsAbs(value) {
+			abs, err := filepath.Abs(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s: %w", name, err)
+			}
+			if err := os.Setenv(name, abs); err != nil {
+				return nil, fmt.Errorf("failed to resolve %s: %w", name, err)
+			}
+		}
+	}
+
+	output, err := exec.Command("git", "rev-parse", "--is-bare-repository", "--is-inside-git-dir",
+		"--git-common-dir", "--absolute-git-dir").Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current directory: %w", err)
+		return nil, errors.New("not in a git repository")
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 4 {
+		return nil, errors.New("not in a git repository")
+	}
+	bare, insideGitDir, commonDir, gitDir := lines[0] == "true", lines[1] == "true", lines[2], lines[3]
+	if commonDir, err = filepath.Abs(commonDir); err != nil {
+		return nil, fmt.Errorf("failed to resolve git directory: %w", err)
+	}
+	if bare {
+		return nil, fmt.Errorf("%s is a bare repository, claude-md needs a working tree", commonDir)
 	}
 
-	// Walk up from current directory to find .git
-	for {
-		gitPath := filepath.Join(dir, ".git")
-		if info, err := os.Stat(gitPath); err == nil {
-			// Accept both .git directory and .git file (worktree)
-			if info.IsDir() || info.Mode().IsRegular() {
-				return &Repository{RootPath: dir}, nil
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	if insideGitDir {
+		// The working tree holds the git directory, unless GIT_WORK_TREE says otherwise
+		cmd.Dir = filepath.Dir(commonDir)
+		// A linked worktree's git directory points at the shared one through commondir
+		if _, err := os.Stat(filepath.Join(gitDir, "commondir")); err == nil {
+			if cmd.Dir, err = linkedWorktreeRoot(gitDir); err != nil {
+				return nil, err
 			}
 		}
+	}
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, errors.New("not in a git working tree")
+	}
+	return &Repository{RootPath: strings.TrimSpace(string(output)), CommonDir: commonDir}, nil
+}
 
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			return nil, errors.New("not in a git repository")
-		}
-		dir = parent
+// linkedWorktreeRoot returns the working tree of a linked worktree from its
+// private git directory, .git/worktrees/<name>, whose gitdir file names the
+// .git file at the root of the working tree
+func linkedWorktreeRoot(gitDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(gitDir, "gitdir"))
+	if err != nil {
+		return "", fmt.Errorf("failed to find the working tree of %s: %w", gitDir, err)
+	}
+	dotGit := strings.TrimSpace(string(content))
+	if !filepath.IsAbs(dotGit) {
+		dotGit = filepath.Join(gitDir, dotGit)
 	}
+	return filepath.Dir(dotGit), nil
 }
 
 // GetOriginURL retrieves the origin remote URL
@@ -63,6 +111,370 @@ func (r *Repository) GetUserEmail() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GitDir returns the absolute path of the repository's git directory, which is
+// private to each worktree
+func (r *Repository) GitDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-dir")
+	cmd.Dir = r.RootPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.New("failed to find git directory")
+	}
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.RootPath, dir)
+	}
+	return dir, nil
+}
+
+// HooksDir returns the absolute path of the directory git runs hooks from,
+// which honors core.hooksPath
+func (r *Repository) HooksDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = r.RootPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.New("failed to find git hooks directory")
+	}
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.RootPath, dir)
+	}
+	return dir, nil
+}
+
+// InfoExcludePath returns the absolute path of the repository's
+// info/exclude file, which is shared by all worktrees
+func (r *Repository) InfoExcludePath() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "info/exclude")
+	cmd.Dir = r.RootPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.New("failed to find git info/exclude file")
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.RootPath, path)
+	}
+	return path, nil
+}
+
+// InfoAttributesPath returns the absolute path of the repository's
+// info/attributes file, which is shared by all worktrees
+func (r *Repository) InfoAttributesPath() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "info/attributes")
+	cmd.Dir = r.RootPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.New("failed to find git info/attributes file")
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.RootPath, path)
+	}
+	return path, nil
+}
+
+// SetConfig sets key in the repository's local git config
+func (r *Repository) SetConfig(key, value string) error {
+	cmd := exec.Command("git", "config", "--local", key, value)
+	cmd.Dir = r.RootPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to run git config: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RemoveConfigSection removes a section, such as filter.name, from the
+// repository's local git config. A missing section is not an error.
+func (r *Repository) RemoveConfigSection(section string) error {
+	cmd := exec.Command("git", "config", "--local", "--get-regexp", "^"+regexp.QuoteMeta(section)+`\.`)
+	cmd.Dir = r.RootPath
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil
+		}
+		return fmt.Errorf("failed to run git config: %w", err)
+	}
+
+	cmd = exec.Command("git", "config", "--local", "--remove-section", section)
+	cmd.Dir = r.RootPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to run git config: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// IsTracked reports whether the repo relative path is tracked by git
+func (r *Repository) IsTracked(repoRelativePath string) (bool, error) {
+	cmd := exec.Command("git", "ls-files", "--error-unmatch", "--", repoRelativePath)
+	cmd.Dir = r.RootPath
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to run git ls-files: %w", err)
+	}
+	return true, nil
+}
+
+// TrackedPaths returns the slash separated repo relative paths of every file in the index
+func (r *Repository) TrackedPaths() (map[string]bool, error) {
+	cmd := exec.Command("git", "ls-files", "-z")
+	cmd.Dir = r.RootPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New("failed to list tracked files")
+	}
+	paths := make(map[string]bool)
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			paths[path] = true
+		}
+	}
+	return paths, nil
+}
+
+// SetSkipWorktree sets or clears the skip-worktree bit of a tracked file, which
+// makes git ignore changes to it in the working tree
+func (r *Repository) SetSkipWorktree(repoRelativePath string, skip bool) error {
+	flag := "--no-skip-worktree"
+	if skip {
+		flag = "--skip-worktree"
+	}
+	cmd := exec.Command("git", "update-index", flag, "--", repoRelativePath)
+	cmd.Dir = r.RootPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to run git update-index: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CheckoutFromIndex writes the index version of a tracked file to the working
+// tree. Unlike git checkout it runs no hooks, so a post-checkout hook calling
+// claude-md cannot wait on the locks of the command calling this.
+func (r *Repository) CheckoutFromIndex(repoRelativePath string) error {
+	cmd := exec.Command("git", "checkout-index", "-f", "--", repoRelativePath)
+	cmd.Dir = r.RootPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to run git checkout-index: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RefreshIndexEntry updates the stat information git keeps for a tracked file
+// whose content, as git sees it after filters, matches the index. git status
+// otherwise lists a file rewritten to the same content as modified. Files with
+// real changes are left unstaged.
+func (r *Repository) RefreshIndexEntry(repoRelativePath string) error {
+	cmd := exec.Command("git", "diff", "--quiet", "--", repoRelativePath)
+	cmd.Dir = r.RootPath
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil
+		}
+		return fmt.Errorf("failed to run git diff: %w", err)
+	}
+
+	cmd = exec.Command("git", "add", "--", repoRelativePath)
+	cmd.Dir = r.RootPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to run git add: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// StagedEntry is a file the next commit adds or changes
+type StagedEntry struct {
+	Path string // Slash separated repo relative path
+	Mode string // Octal git mode, 120000 for symlinks
+	Hash string // Object name of the staged content
+}
+
+// StagedEntries returns the files staged for the next commit, without the
+// ones it deletes
+func (r *Repository) StagedEntries() ([]StagedEntry, error) {
+	cmd := exec.Command("git", "diff", "--cached", "--raw", "-z", "--no-renames", "--no-abbrev", "--diff-filter=AMT")
+	cmd.Dir = r.RootPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New("failed to list staged files")
+	}
+
+	// Each entry is ":<old mode> <new mode> <old hash> <new hash> <status>\0<path>\0"
+	var entries []StagedEntry
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		info := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(info) < 4 {
+			continue
+		}
+		entries = append(entries, StagedEntry{Path: fields[i+1], Mode: info[1], Hash: info[3]})
+	}
+	return entries, nil
+}
+
+// BlobSizes returns the size of each blob, read through one git cat-file
+func (r *Repository) BlobSizes(hashes []string) (map[string]int64, error) {
+	sizes := make(map[string]int64, len(hashes))
+	err := r.catFileBatch("--batch-check", hashes, func(hash string, size int64, _ *bufio.Reader) error {
+		sizes[hash] = size
+		return nil
+	})
+	return sizes, err
+}
+
+// ReadBlobs returns the content of each blob, read through one git cat-file
+func (r *Repository) ReadBlobs(hashes []string) (map[string][]byte, error) {
+	blobs := make(map[string][]byte, len(hashes))
+	err := r.catFileBatch("--batch", hashes, func(hash string, size int64, content *bufio.Reader) error {
+		blob := make([]byte, size+1) // Each blob is followed by a newline
+		if _, err := io.ReadFull(content, blob); err != nil {
+			return err
+		}
+		blobs[hash] = blob[:size]
+		return nil
+	})
+	return blobs, err
+}
+
+// catFileBatch asks git cat-file in the given batch mode about each object in
+// hashes, calling read with the size from each object's header
+func (r *Repository) catFileBatch(mode string, hashes []string, read func(hash string, size int64, output *bufio.Reader) error) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	cmd := exec.Command("git", "cat-file", mode)
+	cmd.Dir = r.RootPath
+	cmd.Stdin = strings.NewReader(strings.Join(hashes, "\n") + "\n")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run git cat-file: %w", err)
+	}
+
+	output := bufio.NewReader(stdout)
+	for _, hash := range hashes {
+		// Each object starts with "<hash> <type> <size>", or "<hash> missing"
+		header, err := output.ReadString('\n')
+		if err != nil {
+			_ = cmd.Wait()
+			return fmt.Errorf("failed to read blob %s", hash)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			_ = cmd.Wait()
+			return fmt.Errorf("failed to read blob %s", hash)
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err == nil {
+			err = read(hash, size, output)
+		}
+		if err != nil {
+			_ = cmd.Wait()
+			return fmt.Errorf("failed to read blob %s", hash)
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("failed to run git cat-file: %w", err)
+	}
+	return nil
+}
+
+// Worktree is one working tree of a repository
+type Worktree struct {
+	Path     string
+	Branch   string // Short branch name, empty when HEAD is detached
+	Bare     bool   // The main repository has no working tree
+	Prunable bool   // The working tree directory no longer exists
+}
+
+// Worktrees returns every working tree of the repository, the main one first
+func (r *Repository) Worktrees() ([]Worktree, error) {
+	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	cmd.Dir = r.RootPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New("failed to list worktrees")
+	}
+
+	var worktrees []Worktree
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		if key == "worktree" {
+			worktrees = append(worktrees, Worktree{Path: value})
+			continue
+		}
+		if len(worktrees) == 0 {
+			continue
+		}
+		current := &worktrees[len(worktrees)-1]
+		switch key {
+		case "branch":
+			current.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			current.Bare = true
+		case "prunable":
+			current.Prunable = true
+		}
+	}
+	return worktrees, nil
+}
+
+// Submodules returns the slash separated repo relative paths of the checked out
+// submodules, including submodules of submodules, each after its parent
+func (r *Repository) Submodules() ([]string, error) {
+	cmd := exec.Command("git", "submodule", "foreach", "--quiet", "--recursive", `printf '%s\0' "$displaypath"`)
+	cmd.Dir = r.RootPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New("failed to list submodules")
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// GlobalTemplateDir returns init.templateDir from the global git config with
+// a leading ~ expanded, or "" if it is not set
+func GlobalTemplateDir() (string, error) {
+	output, err := exec.Command("git", "config", "--global", "--get", "init.templateDir").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to run git config: %w", err)
+	}
+	dir := strings.TrimSpace(string(output))
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dir = filepath.Join(home, strings.TrimPrefix(dir, "~"))
+	}
+	return dir, nil
+}
+
+// SetGlobalTemplateDir sets init.templateDir in the global git config
+func SetGlobalTemplateDir(dir string) error {
+	if output, err := exec.Command("git", "config", "--global", "init.templateDir", dir).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set init.templateDir: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // ExtractRepoName extracts repository name from git remote URL
 // Handles both SSH (git@github.com:user/repo.git) and HTTPS (https://github.com/user/repo.git)
 // Returns repo name as-is from URL (e.g., "kapetan.git" if URL ends with "kapetan.git", "kapetan" if URL ends with "kapetan")
@@ -2,6 +2,7 @@ package git_test
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
@@ -128,55 +129,130 @@ func TestExtractUserFromEmail(t *testing.T) {
 }
 
 func TestFindRepositoryWorktree(t *testing.T) {
-	tmpDir := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(origDir) }()
 
-	// Test with regular .git directory
-	t.Run("RegularGitDirectory", func(t *testing.T) {
-		repoDir := filepath.Join(tmpDir, "regular-repo")
-		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".git"), 0755))
+	// Use EvalSymlinks to handle /var vs /private/var on macOS
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	t.Setenv("GIT_CEILING_DIRECTORIES", tmpDir)
 
-		// Change to repo directory
-		origDir, err := os.Getwd()
-		require.NoError(t, err)
-		defer func() { _ = os.Chdir(origDir) }()
-
-		require.NoError(t, os.Chdir(repoDir))
+	runGit := func(dir string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.email=test@example.com", "-c", "user.name=Test"}, args...)...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
 
-		repo, err := git.FindRepository()
-		require.NoError(t, err)
+	repoDir := filepath.Join(tmpDir, "regular-repo")
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "pkg", "api"), 0755))
+	runGit(repoDir, "init")
+	runGit(repoDir, "commit", "--allow-empty", "-m", "Initial commit")
+	worktreeDir := filepath.Join(tmpDir, "worktree-repo")
+	runGit(repoDir, "worktree", "add", worktreeDir)
 
-		// Use EvalSymlinks to handle /var vs /private/var on macOS
-		expectedPath, err := filepath.EvalSymlinks(repoDir)
-		require.NoError(t, err)
-		actualPath, err := filepath.EvalSymlinks(repo.RootPath)
-		require.NoError(t, err)
-		assert.Equal(t, expectedPath, actualPath)
-	})
+	bareDir := filepath.Join(tmpDir, "dotfiles.git")
+	runGit(tmpDir, "init", "--bare", bareDir)
+	homeDir := filepath.Join(tmpDir, "home")
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".config"), 0755))
 
-	// Test with .git file (worktree)
-	t.Run("GitWorktreeFile", func(t *testing.T) {
-		worktreeDir := filepath.Join(tmpDir, "worktree-repo")
-		require.NoError(t, os.MkdirAll(worktreeDir, 0755))
+	for _, test := range []struct {
+		name      string
+		dir       string
+		env       map[string]string
+		root      string
+		commonDir string
+		err       string
+	}{
+		{
+			name:      "RegularGitDirectory",
+			dir:       filepath.Join(repoDir, "pkg", "api"),
+			root:      repoDir,
+			commonDir: filepath.Join(repoDir, ".git"),
+		},
+		{
+			name:      "GitWorktreeFile",
+			dir:       worktreeDir,
+			root:      worktreeDir,
+			commonDir: filepath.Join(repoDir, ".git"),
+		},
+		{
+			name:      "InsideGitDirectory",
+			dir:       filepath.Join(repoDir, ".git", "refs"),
+			root:      repoDir,
+			commonDir: filepath.Join(repoDir, ".git"),
+		},
+		{
+			name:      "InsideWorktreeGitDirectory",
+			dir:       filepath.Join(repoDir, ".git", "worktrees", "worktree-repo"),
+			root:      worktreeDir,
+			commonDir: filepath.Join(repoDir, ".git"),
+		},
+		{
+			name:      "SeparateGitDirectory",
+			dir:       filepath.Join(homeDir, ".config"),
+			env:       map[string]string{"GIT_DIR": bareDir, "GIT_WORK_TREE": homeDir},
+			root:      homeDir,
+			commonDir: bareDir,
+		},
+		{
+			name: "BareRepository",
+			dir:  bareDir,
+			err:  "is a bare repository",
+		},
+		{
+			name: "NotARepository",
+			dir:  homeDir,
+			err:  "not in a git repository",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+			require.NoError(t, os.Chdir(test.dir))
 
-		// Create .git file (simulating worktree)
-		gitFile := filepath.Join(worktreeDir, ".git")
-		require.NoError(t, os.WriteFile(gitFile, []byte("gitdir: /some/path/.git/worktrees/branch"), 0644))
+			repo, err := git.FindRepository()
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.root, repo.RootPath)
+			assert.Equal(t, test.commonDir, repo.CommonDir)
+		})
+	}
+}
 
-		// Change to worktree directory
-		origDir, err := os.Getwd()
-		require.NoError(t, err)
-		defer func() { _ = os.Chdir(origDir) }()
+func TestCheckoutFromIndexRunsNoHooks(t *testing.T) {
+	repoDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	runGit := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.email=test@example.com", "-c", "user.name=Test"}, args...)...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	runGit("init")
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("team"), 0644))
+	runGit("add", "CLAUDE.md")
+	runGit("commit", "-m", "Add CLAUDE.md")
 
-		require.NoError(t, os.Chdir(worktreeDir))
+	// A claude-md post-checkout hook would wait on the caller's locks
+	marker := filepath.Join(repoDir, "hook-ran")
+	hook := "#!/bin/sh\ntouch '" + marker + "'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".git", "hooks", "post-checkout"), []byte(hook), 0755))
 
-		repo, err := git.FindRepository()
-		require.NoError(t, err)
+	require.NoError(t, os.WriteFile(claudeFile, []byte("personal"), 0644))
+	repo := &git.Repository{RootPath: repoDir}
+	require.NoError(t, repo.CheckoutFromIndex("CLAUDE.md"))
 
-		// Use EvalSymlinks to handle /var vs /private/var on macOS
-		expectedPath, err := filepath.EvalSymlinks(worktreeDir)
-		require.NoError(t, err)
-		actualPath, err := filepath.EvalSymlinks(repo.RootPath)
-		require.NoError(t, err)
-		assert.Equal(t, expectedPath, actualPath)
-	})
+	content, err := os.ReadFile(claudeFile)
+	require.NoError(t, err)
+	assert.Equal(t, "team", string(content))
+	_, err = os.Stat(marker)
+	assert.True(t, os.IsNotExist(err))
 }
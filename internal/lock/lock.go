@@ -0,0 +1,81 @@
+// Package lock provides advisory file locks that keep concurrent claude-md
+// processes from interleaving changes to the same storage or working tree.
+package lock
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is how long Acquire waits for another process to finish
+const DefaultTimeout = 10 * time.Second
+
+// pollInterval is how often Acquire retries a held lock
+const pollInterval = 25 * time.Millisecond
+
+// Lock is an exclusive advisory lock on a file
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes an exclusive lock on path, creating the file if needed, and
+// records this process's pid in it. If another process holds the lock,
+// Acquire retries until timeout and then reports the holder's pid.
+func Acquire(path string, timeout time.Duration) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			pid := holder(path)
+			_ = f.Close()
+			if pid == "" {
+				return nil, fmt.Errorf("another claude-md is running (lock %s)", path)
+			}
+			return nil, fmt.Errorf("another claude-md is running (pid %s)", pid)
+		}
+		time.Sleep(pollInterval)
+	}
+
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release drops the lock
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	_ = l.file.Truncate(0)
+	err := unlock(l.file)
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	l.file = nil
+	return err
+}
+
+// holder returns the pid recorded in the lock file, or "" if unknown
+func holder(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,40 @@
+package lock_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kapetan-io/claude-md.go/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo.lock")
+
+	held, err := lock.Acquire(path, time.Second)
+	require.NoError(t, err)
+
+	t.Run("TimesOutWithHolderPid", func(t *testing.T) {
+		start := time.Now()
+		_, err := lock.Acquire(path, 100*time.Millisecond)
+
+		require.Error(t, err)
+		assert.Equal(t, fmt.Sprintf("another claude-md is running (pid %d)", os.Getpid()), err.Error())
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("WaitsForRelease", func(t *testing.T) {
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = held.Release()
+		}()
+
+		l, err := lock.Acquire(path, time.Second)
+		require.NoError(t, err)
+		require.NoError(t, l.Release())
+	})
+}
@@ -0,0 +1,157 @@
+package merge
+
+import (
+	"bytes"
+)
+
+// Labels names the sides of a merge in conflict markers
+type Labels struct {
+	Ours   string
+	Theirs string
+}
+
+// Result is the outcome of a three-way merge
+type Result struct {
+	Content   []byte
+	Conflicts int // Number of conflicting hunks left with markers
+}
+
+// ThreeWay performs a line based three-way merge of ours and theirs against base
+// Hunks changed on only one side are taken from that side. Hunks changed
+// differently on both sides are written with git style conflict markers.
+func ThreeWay(base, ours, theirs []byte, labels Labels) Result {
+	o := splitLines(base)
+	a := splitLines(ours)
+	b := splitLines(theirs)
+
+	matchA := matchLines(o, a)
+	matchB := matchLines(o, b)
+
+	var out bytes.Buffer
+	var conflicts int
+	po, pa, pb := 0, 0, 0
+
+	for {
+		// Copy lines that are unchanged on both sides
+		for po < len(o) && matchA[po] == pa && matchB[po] == pb {
+			out.Write(o[po])
+			po, pa, pb = po+1, pa+1, pb+1
+		}
+
+		// Find the next base line both sides still share
+		no, na, nb := len(o), len(a), len(b)
+		for x := po; x < len(o); x++ {
+			if matchA[x] >= 0 && matchB[x] >= 0 {
+				no, na, nb = x, matchA[x], matchB[x]
+				break
+			}
+		}
+
+		if po == no && pa == na && pb == nb {
+			break
+		}
+
+		baseHunk, oursHunk, theirsHunk := o[po:no], a[pa:na], b[pb:nb]
+		switch {
+		case equalLines(oursHunk, baseHunk):
+			writeLines(&out, theirsHunk)
+		case equalLines(theirsHunk, baseHunk), equalLines(oursHunk, theirsHunk):
+			writeLines(&out, oursHunk)
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< " + labels.Ours + "\n")
+			writeLines(&out, terminate(oursHunk))
+			out.WriteString("=======\n")
+			writeLines(&out, terminate(theirsHunk))
+			out.WriteString(">>>>>>> " + labels.Theirs + "\n")
+		}
+
+		po, pa, pb = no, na, nb
+	}
+
+	return Result{Content: out.Bytes(), Conflicts: conflicts}
+}
+
+// splitLines splits content into lines, keeping the line endings
+func splitLines(content []byte) [][]byte {
+	var lines [][]byte
+	for len(content) > 0 {
+		i := bytes.IndexByte(content, '\n')
+		if i < 0 {
+			lines = append(lines, content)
+			break
+		}
+		lines = append(lines, content[:i+1])
+		content = content[i+1:]
+	}
+	return lines
+}
+
+// matchLines returns, for each line of base, the index of the matching line in
+// other according to a longest common subsequence, or -1 if the line was removed
+func matchLines(base, other [][]byte) []int {
+	n, m := len(base), len(other)
+
+	// lcs[i][j] is the LCS length of base[i:] and other[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if bytes.Equal(base[i], other[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	match := make([]int, n)
+	for i := range match {
+		match[i] = -1
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case bytes.Equal(base[i], other[j]):
+			match[i] = j
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return match
+}
+
+func equalLines(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(out *bytes.Buffer, lines [][]byte) {
+	for _, line := range lines {
+		out.Write(line)
+	}
+}
+
+// terminate makes sure the last line ends with a newline so markers start on their own line
+func terminate(lines [][]byte) [][]byte {
+	if len(lines) == 0 {
+		return lines
+	}
+	last := lines[len(lines)-1]
+	if len(last) > 0 && last[len(last)-1] == '\n' {
+		return lines
+	}
+	out := append([][]byte{}, lines[:len(lines)-1]...)
+	return append(out, append(append([]byte{}, last...), '\n'))
+}
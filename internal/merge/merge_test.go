@@ -0,0 +1,87 @@
+package merge_test
+
+import (
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/merge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThreeWay(t *testing.T) {
+	labels := merge.Labels{Ours: "storage", Theirs: "repo"}
+
+	for _, test := range []struct {
+		name          string
+		base          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name:   "NoChanges",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "OnlyOursChanged",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "OnlyTheirsChanged",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\nd\n",
+			want:   "a\nb\nc\nd\n",
+		},
+		{
+			name:   "DisjointChanges",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "SameChangeBothSides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nX\nc\n",
+			theirs: "a\nX\nc\n",
+			want:   "a\nX\nc\n",
+		},
+		{
+			name:          "ConflictingChanges",
+			base:          "a\nb\nc\n",
+			ours:          "a\nours\nc\n",
+			theirs:        "a\ntheirs\nc\n",
+			want:          "a\n<<<<<<< storage\nours\n=======\ntheirs\n>>>>>>> repo\nc\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "EmptyBase",
+			base:          "",
+			ours:          "one\n",
+			theirs:        "two\n",
+			want:          "<<<<<<< storage\none\n=======\ntwo\n>>>>>>> repo\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "MissingTrailingNewline",
+			base:          "a\n",
+			ours:          "a\nb",
+			theirs:        "a\nc",
+			want:          "a\n<<<<<<< storage\nb\n=======\nc\n>>>>>>> repo\n",
+			wantConflicts: 1,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := merge.ThreeWay([]byte(test.base), []byte(test.ours), []byte(test.theirs), labels)
+			assert.Equal(t, test.want, string(got.Content))
+			assert.Equal(t, test.wantConflicts, got.Conflicts)
+		})
+	}
+}
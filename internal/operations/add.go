@@ -0,0 +1,75 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// AddOptions contains options for add operation
+type AddOptions struct {
+	RepoRoot         string
+	RepoRelativePath string // Path of the new file in the repo
+	Content          []byte
+	PathConverter    *storage.PathConverter
+	Manifest         *storage.Manifest
+	LinkMode         storage.LinkMode  // Overrides the mode recorded in Manifest when set
+	LinkStyle        storage.LinkStyle // Overrides the style recorded in Manifest when set
+}
+
+// AddFile creates a new stored file with the given content and links it into
+// the working tree. Neither the stored file nor the working tree path may exist.
+// Returns the storage path.
+func AddFile(opts AddOptions) (string, error) {
+	targetPath := filepath.Join(opts.RepoRoot, opts.RepoRelativePath)
+	if _, err := os.Lstat(targetPath); err == nil {
+		return "", fmt.Errorf("%s already exists, use save to manage it", opts.RepoRelativePath)
+	}
+	if info, err := os.Stat(filepath.Dir(targetPath)); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("directory %s does not exist", filepath.Dir(opts.RepoRelativePath))
+	}
+
+	storagePath, err := opts.PathConverter.GetStoragePath(opts.RepoRelativePath)
+	if err != nil {
+		return "", err
+	}
+	if err := opts.PathConverter.EnsureStorageDir(); err != nil {
+		return "", err
+	}
+
+	if err := storage.CreateAtomic(storagePath, opts.Content, 0644); err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("storage already has a file for %s, use restore to link it", opts.RepoRelativePath)
+		}
+		return "", fmt.Errorf("failed to create stored file: %w", err)
+	}
+
+	absStoragePath, err := filepath.Abs(storagePath)
+	if err != nil {
+		_ = os.Remove(storagePath)
+		return "", err
+	}
+
+	storageName := filepath.Base(storagePath)
+	mode := linkModeFor(opts.Manifest, storageName, opts.LinkMode)
+	if err := placeFile(absStoragePath, targetPath, mode, linkStyleFor(opts.Manifest, opts.LinkStyle)); err != nil {
+		_ = os.Remove(storagePath)
+		return "", fmt.Errorf("failed to link %s: %w", opts.RepoRelativePath, err)
+	}
+
+	if _, err := opts.PathConverter.RecordVersion(storageName, opts.Content); err != nil {
+		return storagePath, err
+	}
+	opts.Manifest.Record(storageName, opts.Content)
+	if opts.LinkMode != "" {
+		opts.Manifest.SetLinkMode(storageName, opts.LinkMode)
+	}
+	if mode != storage.LinkSymlink {
+		if absTarget, err := filepath.Abs(targetPath); err == nil {
+			opts.Manifest.SetCopyHash(storageName, absTarget, storage.HashContent(opts.Content))
+		}
+	}
+	return storagePath, nil
+}
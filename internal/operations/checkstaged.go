@@ -0,0 +1,91 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// StagedFile is a file the next commit adds or changes, with its staged content
+type StagedFile struct {
+	Path    string // Slash separated repo relative path
+	Symlink bool
+	Content []byte // The link target for symlinks
+}
+
+// StagedProblem is a staged file that would commit something personal
+type StagedProblem struct {
+	Path   string
+	Reason string
+	Fix    string // Shell command that unstages the file
+}
+
+// CheckStagedOptions configures CheckStaged
+type CheckStagedOptions struct {
+	RepoRoot    string
+	StorageRoot string // Base of all claude-md storage, ~/.claude/claude-md
+	StoredFiles []files.StoredFile
+}
+
+// CheckStaged returns the staged files that must not be committed: symlinks
+// into claude-md storage or out of the repository, which only resolve on this
+// machine, and files whose content is a stored personal file
+func CheckStaged(staged []StagedFile, opts CheckStagedOptions) []StagedProblem {
+	storedByHash := make(map[string]string)
+	for _, stored := range opts.StoredFiles {
+		content, err := os.ReadFile(stored.StoragePath)
+		// Every empty file would match an empty stored one
+		if err != nil || len(strings.TrimSpace(string(content))) == 0 {
+			continue
+		}
+		storedByHash[storage.HashContent(content)] = stored.RepoRelativePath
+	}
+
+	var problems []StagedProblem
+	for _, file := range staged {
+		var reason string
+		if file.Symlink {
+			reason = checkStagedLink(file, opts)
+		} else if stored, ok := storedByHash[storage.HashContent(file.Content)]; ok {
+			reason = fmt.Sprintf("content matches the stored personal %s", stored)
+		}
+		if reason != "" {
+			problems = append(problems, StagedProblem{
+				Path:   file.Path,
+				Reason: reason,
+				Fix:    "git restore --staged -- " + shellQuote(file.Path),
+			})
+		}
+	}
+	return problems
+}
+
+// checkStagedLink returns why a staged symlink must not be committed, or ""
+func checkStagedLink(file StagedFile, opts CheckStagedOptions) string {
+	target := string(file.Content)
+	resolved := target
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(opts.RepoRoot, filepath.Dir(filepath.FromSlash(file.Path)), target)
+	}
+	resolved = filepath.Clean(resolved)
+
+	if opts.StorageRoot != "" && files.IsWithin(resolved, opts.StorageRoot) {
+		return fmt.Sprintf("symlink into claude-md storage (%s)", target)
+	}
+	if files.CanonicalPath(resolved) != files.CanonicalPath(opts.RepoRoot) && !files.IsWithin(resolved, opts.RepoRoot) {
+		return fmt.Sprintf("symlink to %s, outside the repository", target)
+	}
+	return ""
+}
+
+// shellQuote quotes s for a POSIX shell when it holds anything but plain path characters
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789._-/+=@%:,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -22,24 +22,30 @@ type ClearResult struct {
 type ClearOptions struct {
 	RepoRoot      string
 	PathConverter *storage.PathConverter
+	Manifest      *storage.Manifest // When set, hardlinks and copies of stored files are cleared too
+	Filter        files.Filter      // Limits which files are cleared, the zero value clears all
+	AllNamespaces bool              // Also remove symlinks into any other claude-md storage
+	Dangling      bool              // Also remove symlinks whose target does not exist
 }
 
-// ClearSymlinks removes all CLAUDE.md symlinks from repository
+// ClearSymlinks removes all CLAUDE.md symlinks into this repository's storage.
+// Ownership is decided by whole path components, so links into a sibling
+// repository whose name shares a prefix are never touched.
 func ClearSymlinks(opts ClearOptions) []ClearResult {
 	var results []ClearResult
 
 	// Find all CLAUDE.md files in repository
-	claudeFiles, err := files.FindClaudeFiles(opts.RepoRoot)
+	claudeFiles, err := files.FindManagedFiles(opts.RepoRoot, opts.PathConverter.Patterns)
 	if err != nil {
 		return results
 	}
 
-	// Get storage directory for validation
-	storageDir := opts.PathConverter.GetRepoStorageDir()
-
-	// Filter to only symlinks
-	for _, file := range claudeFiles {
+	// Filter to only symlinks, plus hardlinks and copies the manifest knows about
+	for _, file := range opts.Filter.ClaudeFiles(claudeFiles) {
 		if !file.IsSymlink {
+			if result, ok := clearCopy(file, opts); ok {
+				results = append(results, result)
+			}
 			continue
 		}
 
@@ -47,8 +53,8 @@ func ClearSymlinks(opts ClearOptions) []ClearResult {
 			RepoRelativePath: file.RepoRelativePath,
 		}
 
-		// Read symlink target
-		target, err := os.Readlink(file.AbsolutePath)
+		// Read symlink target, resolving relative targets against the link's directory
+		absTarget, err := files.ReadLinkTarget(file.AbsolutePath)
 		if err != nil {
 			result.Skipped = true
 			result.SkipReason = "failed to read symlink"
@@ -57,18 +63,10 @@ func ClearSymlinks(opts ClearOptions) []ClearResult {
 			continue
 		}
 
-		// Verify symlink points to our storage directory
-		// Convert to absolute path for comparison
-		absTarget, err := filepath.Abs(target)
-		if err != nil {
-			// If it's already absolute, use it as-is
-			absTarget = target
-		}
-
-		// Check if target is within our storage directory
-		if !strings.HasPrefix(absTarget, storageDir) {
+		// Check if target is within our storage directory, or another one we were asked to clear
+		if reason := clearSkipReason(absTarget, opts); reason != "" {
 			result.Skipped = true
-			result.SkipReason = "symlink points outside storage"
+			result.SkipReason = reason
 			results = append(results, result)
 			continue
 		}
@@ -85,3 +83,78 @@ func ClearSymlinks(opts ClearOptions) []ClearResult {
 
 	return results
 }
+
+// clearSkipReason returns why a symlink to absTarget must be left alone, or "" to remove it
+func clearSkipReason(absTarget string, opts ClearOptions) string {
+	if files.IsWithin(absTarget, opts.PathConverter.GetRepoStorageDir()) {
+		return ""
+	}
+	if opts.Dangling {
+		if _, err := os.Stat(absTarget); os.IsNotExist(err) {
+			return ""
+		}
+	}
+	if inNamespace(absTarget, opts.PathConverter.GetStorageBaseDir()) {
+		if opts.AllNamespaces {
+			return ""
+		}
+		return "symlink points into another claude-md namespace (use --all-namespaces)"
+	}
+	return "symlink points outside storage"
+}
+
+// inNamespace reports whether absTarget is a file in some <user>/<repo> directory below baseDir
+func inNamespace(absTarget, baseDir string) bool {
+	if !files.IsWithin(absTarget, baseDir) {
+		return false
+	}
+	rel, err := filepath.Rel(files.CanonicalPath(baseDir), files.CanonicalPath(absTarget))
+	return err == nil && len(strings.Split(rel, string(filepath.Separator))) >= 3
+}
+
+// clearCopy removes a hardlink or copy of a stored file if it still matches storage.
+// Returns false if the file is not a placed copy.
+func clearCopy(file files.ClaudeFile, opts ClearOptions) (ClearResult, bool) {
+	result := ClearResult{
+		RepoRelativePath: file.RepoRelativePath,
+	}
+	if opts.Manifest == nil {
+		return result, false
+	}
+
+	storagePath, err := opts.PathConverter.GetStoragePath(file.RepoRelativePath)
+	if err != nil {
+		return result, false
+	}
+	storageName := filepath.Base(storagePath)
+	mode := opts.Manifest.LinkModeFor(storageName)
+	if mode == storage.LinkSymlink {
+		return result, false
+	}
+	if _, err := os.Stat(storagePath); err != nil {
+		return result, false
+	}
+
+	placed, err := isPlacedCopy(storagePath, file.AbsolutePath, mode)
+	if err != nil {
+		result.Skipped = true
+		result.SkipReason = "failed to compare with storage"
+		result.Error = err
+		return result, true
+	}
+	if !placed {
+		result.Skipped = true
+		result.SkipReason = "copy differs from storage, run sync first"
+		return result, true
+	}
+
+	if err := os.Remove(file.AbsolutePath); err != nil {
+		result.Error = err
+		return result, true
+	}
+	if absPath, err := filepath.Abs(file.AbsolutePath); err == nil {
+		opts.Manifest.RemoveCopy(storageName, absPath)
+	}
+	result.Success = true
+	return result, true
+}
@@ -107,3 +107,103 @@ func TestClearSymlinksValidation(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestClearSymlinksOwnership(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoDir := filepath.Join(tmpDir, "repo")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+
+	// Layout mirrors ~/.claude/claude-md/<user>/<repo>
+	baseDir := filepath.Join(tmpDir, "claude-md")
+	pc := &storage.PathConverter{
+		StorageRoot: filepath.Join(baseDir, "me"),
+		RepoName:    "api",
+	}
+
+	link := func(t *testing.T, target string) string {
+		require.NoError(t, os.MkdirAll(filepath.Dir(target), 0700))
+		require.NoError(t, os.WriteFile(target, []byte("content"), 0644))
+		claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+		_ = os.Remove(claudeFile)
+		require.NoError(t, os.Symlink(target, claudeFile))
+		return claudeFile
+	}
+
+	for _, test := range []struct {
+		name          string
+		target        string
+		allNamespaces bool
+		removed       bool
+		skipReason    string
+	}{
+		{
+			name:    "OwnStorage",
+			target:  filepath.Join(baseDir, "me", "api", "CLAUDE.md"),
+			removed: true,
+		},
+		{
+			name:       "SiblingWithSharedPrefix",
+			target:     filepath.Join(baseDir, "me", "api-gateway", "CLAUDE.md"),
+			skipReason: "symlink points into another claude-md namespace (use --all-namespaces)",
+		},
+		{
+			name:          "SiblingWithAllNamespaces",
+			target:        filepath.Join(baseDir, "me", "api-gateway", "CLAUDE.md"),
+			allNamespaces: true,
+			removed:       true,
+		},
+		{
+			name:          "OtherUserWithAllNamespaces",
+			target:        filepath.Join(baseDir, "you", "api", "CLAUDE.md"),
+			allNamespaces: true,
+			removed:       true,
+		},
+		{
+			name:          "OutsideStorageWithAllNamespaces",
+			target:        filepath.Join(tmpDir, "elsewhere", "CLAUDE.md"),
+			allNamespaces: true,
+			skipReason:    "symlink points outside storage",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			claudeFile := link(t, test.target)
+
+			results := operations.ClearSymlinks(operations.ClearOptions{
+				RepoRoot:      repoDir,
+				PathConverter: pc,
+				AllNamespaces: test.allNamespaces,
+			})
+
+			require.Len(t, results, 1)
+			assert.Equal(t, test.removed, results[0].Success)
+			assert.Equal(t, test.skipReason, results[0].SkipReason)
+
+			_, err := os.Lstat(claudeFile)
+			assert.Equal(t, test.removed, os.IsNotExist(err))
+		})
+	}
+
+	t.Run("Dangling", func(t *testing.T) {
+		claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+		_ = os.Remove(claudeFile)
+		require.NoError(t, os.Symlink(filepath.Join(tmpDir, "gone", "CLAUDE.md"), claudeFile))
+
+		results := operations.ClearSymlinks(operations.ClearOptions{
+			RepoRoot:      repoDir,
+			PathConverter: pc,
+		})
+		require.Len(t, results, 1)
+		assert.True(t, results[0].Skipped)
+
+		results = operations.ClearSymlinks(operations.ClearOptions{
+			RepoRoot:      repoDir,
+			PathConverter: pc,
+			Dangling:      true,
+		})
+		require.Len(t, results, 1)
+		assert.True(t, results[0].Success)
+
+		_, err := os.Lstat(claudeFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -0,0 +1,67 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SaveConflictPolicy decides what save does when storage already has the file
+type SaveConflictPolicy string
+
+const (
+	SaveConflictSkip      SaveConflictPolicy = "skip"      // Leave both copies alone
+	SaveConflictOverwrite SaveConflictPolicy = "overwrite" // Replace storage, keeping a backup
+	SaveConflictKeepBoth  SaveConflictPolicy = "keep-both" // Store repo content as a backup in storage
+	SaveConflictMerge     SaveConflictPolicy = "merge"     // Three-way merge against the last saved version
+	SaveConflictPrompt    SaveConflictPolicy = "prompt"    // Ask for each conflicting file
+)
+
+// SaveConflictPolicies lists the accepted values for save --on-conflict
+var SaveConflictPolicies = []SaveConflictPolicy{
+	SaveConflictSkip, SaveConflictOverwrite, SaveConflictKeepBoth, SaveConflictMerge, SaveConflictPrompt,
+}
+
+// ParseSaveConflictPolicy validates a save --on-conflict value
+func ParseSaveConflictPolicy(s string) (SaveConflictPolicy, error) {
+	for _, p := range SaveConflictPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("invalid conflict policy %q (must be one of: %s)", s, joinPolicies(SaveConflictPolicies))
+}
+
+func joinPolicies[T ~string](policies []T) string {
+	names := make([]string, len(policies))
+	for i, p := range policies {
+		names[i] = string(p)
+	}
+	return strings.Join(names, ", ")
+}
+
+// RestoreConflictPolicy decides what restore does when a regular file is in the way
+type RestoreConflictPolicy string
+
+const (
+	RestoreConflictSkip           RestoreConflictPolicy = "skip"            // Leave the file alone
+	RestoreConflictAdoptIdentical RestoreConflictPolicy = "adopt-identical" // Replace it only if it matches storage
+	RestoreConflictReplace        RestoreConflictPolicy = "replace"         // Replace it with the symlink
+	RestoreConflictBackup         RestoreConflictPolicy = "backup"          // Move it to the backups in storage, then link
+	RestoreConflictPrompt         RestoreConflictPolicy = "prompt"          // Ask for each conflicting file
+)
+
+// RestoreConflictPolicies lists the accepted values for restore --on-conflict
+var RestoreConflictPolicies = []RestoreConflictPolicy{
+	RestoreConflictSkip, RestoreConflictAdoptIdentical, RestoreConflictReplace, RestoreConflictBackup,
+	RestoreConflictPrompt,
+}
+
+// ParseRestoreConflictPolicy validates a restore --on-conflict value
+func ParseRestoreConflictPolicy(s string) (RestoreConflictPolicy, error) {
+	for _, p := range RestoreConflictPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("invalid conflict policy %q (must be one of: %s)", s, joinPolicies(RestoreConflictPolicies))
+}
@@ -0,0 +1,154 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// LinkProblem describes a CLAUDE.md symlink that does not lead to a stored file
+type LinkProblem struct {
+	RepoRelativePath string
+	Target           string
+	Reason           string // "dangling", "points outside storage"
+}
+
+// CheckLinks reports CLAUDE.md symlinks in the working tree that are dangling
+// or point somewhere other than this repository's storage
+func CheckLinks(claudeFiles []files.ClaudeFile, storageDir string) []LinkProblem {
+	var problems []LinkProblem
+
+	for _, file := range claudeFiles {
+		if !file.IsSymlink {
+			continue
+		}
+
+		problem := LinkProblem{RepoRelativePath: file.RepoRelativePath}
+		problem.Target, _ = os.Readlink(file.AbsolutePath)
+
+		target, err := files.ReadLinkTarget(file.AbsolutePath)
+		if err != nil {
+			problem.Reason = fmt.Sprintf("unreadable: %v", err)
+			problems = append(problems, problem)
+			continue
+		}
+		if _, err := os.Stat(target); err != nil {
+			problem.Reason = "dangling"
+			problems = append(problems, problem)
+			continue
+		}
+		if !files.IsWithin(target, storageDir) {
+			problem.Reason = "points outside storage"
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems
+}
+
+// namesStoredFile reports whether a symlink target ends in the
+// <user>/<repo>/<storage name> path of storagePath, wherever storage was then
+func namesStoredFile(target, storagePath string) bool {
+	repoDir := filepath.Dir(storagePath)
+	suffix := filepath.Join(filepath.Base(filepath.Dir(repoDir)), filepath.Base(repoDir), filepath.Base(storagePath))
+	return strings.HasSuffix(filepath.Clean(target), string(filepath.Separator)+suffix)
+}
+
+// ConvertResult represents the result of converting a symlink to another link style
+type ConvertResult struct {
+	RepoRelativePath string
+	OldTarget        string
+	NewTarget        string
+	Success          bool
+	Skipped          bool
+	SkipReason       string // "already converted", "not linked", etc.
+	Error            error
+}
+
+// ConvertOptions contains options for link conversion
+type ConvertOptions struct {
+	RepoRoot  string
+	LinkStyle storage.LinkStyle
+}
+
+// ConvertLinks rewrites symlinks to stored files in the requested style.
+// Each link is replaced atomically, so it always points at its stored file.
+func ConvertLinks(storedFiles []files.StoredFile, opts ConvertOptions) []ConvertResult {
+	var results []ConvertResult
+
+	for _, stored := range storedFiles {
+		linkPath := filepath.Join(opts.RepoRoot, stored.RepoRelativePath)
+		isSymlink, err := files.IsSymlink(linkPath)
+		if err != nil || !isSymlink {
+			continue
+		}
+
+		result := ConvertResult{
+			RepoRelativePath: stored.RepoRelativePath,
+		}
+
+		// A link into storage from before the home directory moved no longer
+		// resolves, but still names the stored file
+		correct, err := pointsTo(linkPath, stored.StoragePath)
+		if err != nil || !correct {
+			raw, readErr := os.Readlink(linkPath)
+			correct = readErr == nil && namesStoredFile(raw, stored.StoragePath)
+		}
+		if !correct {
+			result.Skipped = true
+			result.SkipReason = "points elsewhere"
+			result.Error = err
+			results = append(results, result)
+			continue
+		}
+
+		absStoragePath, err := filepath.Abs(stored.StoragePath)
+		if err != nil {
+			result.Skipped = true
+			result.SkipReason = "absolute path failed"
+			result.Error = err
+			results = append(results, result)
+			continue
+		}
+
+		result.OldTarget, _ = os.Readlink(linkPath)
+		result.NewTarget, err = symlinkTarget(absStoragePath, linkPath, opts.LinkStyle)
+		if err != nil {
+			result.Skipped = true
+			result.SkipReason = "failed to compute target"
+			result.Error = err
+			results = append(results, result)
+			continue
+		}
+
+		if result.OldTarget == result.NewTarget {
+			result.Skipped = true
+			result.SkipReason = "already converted"
+			results = append(results, result)
+			continue
+		}
+
+		tmp := linkPath + ".claude-md.tmp"
+		_ = os.Remove(tmp)
+		if err := os.Symlink(result.NewTarget, tmp); err != nil {
+			result.Error = fmt.Errorf("failed to create symlink: %w", err)
+			results = append(results, result)
+			continue
+		}
+		if err := os.Rename(tmp, linkPath); err != nil {
+			_ = os.Remove(tmp)
+			result.Error = fmt.Errorf("failed to replace symlink: %w", err)
+			results = append(results, result)
+			continue
+		}
+
+		result.Success = true
+		results = append(results, result)
+	}
+
+	return results
+}
@@ -0,0 +1,149 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// EjectResult represents the result of ejecting a single file
+type EjectResult struct {
+	RepoRelativePath string
+	StoragePath      string
+	Success          bool
+	Purged           bool // The stored copy was removed as well
+	Skipped          bool
+	SkipReason       string // "not linked to storage", etc.
+	Warning          string
+	Error            error
+}
+
+// EjectOptions contains options for eject operation
+type EjectOptions struct {
+	RepoRoot      string
+	PathConverter *storage.PathConverter
+	Manifest      *storage.Manifest // Required, records ejected files and forgets placed copies and purged files
+	Purge         bool              // Remove the stored copy and its history once the file is ejected
+}
+
+// EjectFiles replaces the working tree link of each stored file with a regular
+// file holding the stored content, so claude-md no longer manages it.
+// Each file is swapped in with a rename, so the path always holds either the
+// link or the finished file.
+func EjectFiles(storedFiles []files.StoredFile, opts EjectOptions) []EjectResult {
+	var results []EjectResult
+
+	for _, stored := range storedFiles {
+		result := EjectResult{
+			RepoRelativePath: stored.RepoRelativePath,
+			StoragePath:      stored.StoragePath,
+		}
+		ejectFile(stored, opts, &result)
+		if result.Success && opts.Purge {
+			purgeStored(stored, opts, &result)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func ejectFile(stored files.StoredFile, opts EjectOptions, result *EjectResult) {
+	targetPath := filepath.Join(opts.RepoRoot, stored.RepoRelativePath)
+
+	skip := func(reason string, err error) {
+		result.Skipped = true
+		result.SkipReason = reason
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: %s", stored.RepoRelativePath, reason)
+	}
+
+	info, err := os.Lstat(targetPath)
+	if err != nil {
+		skip("not in the working tree", nil)
+		return
+	}
+
+	mode := opts.Manifest.LinkModeFor(stored.StorageFilename)
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		correct, err := pointsTo(targetPath, stored.StoragePath)
+		if err != nil || !correct {
+			skip("not linked to storage", err)
+			return
+		}
+	case info.Mode().IsRegular() && mode != storage.LinkSymlink:
+		placed, err := isPlacedCopy(stored.StoragePath, targetPath, mode)
+		if err != nil || !placed {
+			skip("copy differs from storage, run sync first", err)
+			return
+		}
+	default:
+		skip("not linked to storage", nil)
+		return
+	}
+
+	// A copy already is an independent file, only a link needs replacing
+	if !(info.Mode().IsRegular() && mode == storage.LinkCopy) {
+		if err := replaceWithContent(stored.StoragePath, targetPath); err != nil {
+			skip("failed to replace link", err)
+			return
+		}
+	}
+
+	if absPath, err := filepath.Abs(targetPath); err == nil {
+		opts.Manifest.RemoveCopy(stored.StorageFilename, absPath)
+		opts.Manifest.SetEjected(stored.StorageFilename, absPath, true)
+	}
+	opts.Manifest.RemovePending(stored.RepoRelativePath)
+	result.Success = true
+}
+
+// purgeStored removes an ejected file's stored copy, history and manifest entry
+func purgeStored(stored files.StoredFile, opts EjectOptions, result *EjectResult) {
+	if err := os.Remove(stored.StoragePath); err != nil {
+		result.Error = err
+		result.Warning = fmt.Sprintf("%s was ejected but its stored copy could not be removed: %v",
+			stored.RepoRelativePath, err)
+		return
+	}
+	if err := opts.PathConverter.RemoveHistory(stored.StorageFilename); err != nil {
+		result.Warning = fmt.Sprintf("%s was purged but its history could not be removed: %v",
+			stored.RepoRelativePath, err)
+	}
+	opts.Manifest.Remove(stored.StorageFilename)
+	result.Purged = true
+}
+
+// replaceWithContent atomically replaces targetPath with a regular file holding storagePath's
+// content, mode and modification time
+func replaceWithContent(storagePath, targetPath string) error {
+	content, err := os.ReadFile(storagePath)
+	if err != nil {
+		return err
+	}
+
+	meta, err := storage.ReadMeta(storagePath)
+	if err != nil {
+		return err
+	}
+
+	tmp := targetPath + ".claude-md.tmp"
+	_ = os.Remove(tmp)
+	if err := os.WriteFile(tmp, content, meta.Mode); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := storage.ApplyMeta(tmp, meta); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, targetPath); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
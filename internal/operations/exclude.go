@@ -0,0 +1,95 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// ExcludedPaths returns the repo paths of stored files that are placed in the
+// working tree, which git should not offer to commit. Regular files in the way
+// of a link belong to the user and are left visible.
+func ExcludedPaths(storedFiles []files.StoredFile, opts StatusOptions) []string {
+	var paths []string
+	for _, status := range Status(nil, storedFiles, opts) {
+		if status.State == StateLinked || status.State == StateModified {
+			paths = append(paths, status.RepoRelativePath)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// WriteExcludeBlock replaces the claude-md block of a gitignore style file with
+// anchored patterns for paths, removing the block when paths is empty. Lines
+// outside the block are kept. Returns whether the file changed.
+func WriteExcludeBlock(excludePath string, paths []string) (bool, error) {
+	var lines []string
+	for _, path := range paths {
+		lines = append(lines, "/"+escapeIgnorePattern(path))
+	}
+	return writeBlock(excludePath, "# Files linked by claude-md, updated automatically", lines)
+}
+
+// writeBlock replaces the claude-md block of a file git reads, such as an
+// excludes or attributes file, with comment and lines, removing the block when
+// lines is empty. Lines outside the block are kept. Returns whether the file changed.
+func writeBlock(path, comment string, lines []string) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	var block string
+	if len(lines) > 0 {
+		block = blockStart + "\n" + comment + "\n" + strings.Join(lines, "\n") + "\n" + blockEnd + "\n"
+	}
+
+	content := string(existing)
+	if start := strings.Index(content, blockStart); start >= 0 {
+		// Keep the block where the user may have moved it
+		rest := removeBlock(content[start:])
+		content = content[:start] + block + rest
+	} else if block != "" {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += block
+	}
+
+	if content == string(existing) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	if err := storage.WriteAtomic(path, []byte(content), mode); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// escapeIgnorePattern makes a path match itself literally as a gitignore pattern
+func escapeIgnorePattern(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune(`\*?[!#`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	escaped := b.String()
+	// Trailing spaces are ignored unless escaped
+	if trimmed := strings.TrimRight(escaped, " "); trimmed != escaped {
+		escaped = trimmed + strings.Repeat(`\ `, len(escaped)-len(trimmed))
+	}
+	return escaped
+}
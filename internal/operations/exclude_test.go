@@ -0,0 +1,50 @@
+package operations_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteExcludeBlock(t *testing.T) {
+	excludePath := filepath.Join(t.TempDir(), "info", "exclude")
+
+	changed, err := operations.WriteExcludeBlock(excludePath, nil)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	_, err = os.Stat(excludePath)
+	assert.True(t, os.IsNotExist(err), "nothing to exclude creates no file")
+
+	// Paths are matched literally
+	changed, err = operations.WriteExcludeBlock(excludePath, []string{"#notes/CLAUDE.md", "a[1]/*/CLAUDE.md "})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	content, err := os.ReadFile(excludePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "/\\#notes/CLAUDE.md\n/a\\[1]/\\*/CLAUDE.md\\ \n")
+
+	// Lines around the block are kept where they are
+	require.NoError(t, os.WriteFile(excludePath, []byte("before\n"+string(content)+"after\n"), 0644))
+	changed, err = operations.WriteExcludeBlock(excludePath, []string{"CLAUDE.md"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	content, err = os.ReadFile(excludePath)
+	require.NoError(t, err)
+	assert.Equal(t, "before\n# >>> claude-md >>>\n# Files linked by claude-md, updated automatically\n"+
+		"/CLAUDE.md\n# <<< claude-md <<<\nafter\n", string(content))
+
+	changed, err = operations.WriteExcludeBlock(excludePath, []string{"CLAUDE.md"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	changed, err = operations.WriteExcludeBlock(excludePath, nil)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	content, err = os.ReadFile(excludePath)
+	require.NoError(t, err)
+	assert.Equal(t, "before\nafter\n", string(content))
+}
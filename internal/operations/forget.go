@@ -0,0 +1,92 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// ForgetResult represents the result of forgetting a single stored file
+type ForgetResult struct {
+	RepoRelativePath string
+	TrashPath        string   // Where the stored copy was moved
+	RemovedLinks     []string // Absolute paths of the symlinks removed, across all clones
+	Success          bool
+	Skipped          bool
+	SkipReason       string // "not a symlink", etc.
+	Warning          string
+	Error            error
+}
+
+// ForgetOptions contains options for forget operation
+type ForgetOptions struct {
+	RepoRoot      string
+	PathConverter *storage.PathConverter
+	Manifest      *storage.Manifest // Required, supplies the known clones and drops the forgotten entries
+}
+
+// ForgetFiles moves each stored file into the trash and removes the symlinks to
+// it from this clone and every other clone recorded in the manifest.
+// A file whose working tree path holds anything but a symlink is left alone,
+// since removing storage would be the only copy of what the user sees.
+func ForgetFiles(storedFiles []files.StoredFile, opts ForgetOptions) []ForgetResult {
+	var results []ForgetResult
+
+	for _, stored := range storedFiles {
+		result := ForgetResult{
+			RepoRelativePath: stored.RepoRelativePath,
+		}
+		forgetFile(stored, opts, &result)
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func forgetFile(stored files.StoredFile, opts ForgetOptions, result *ForgetResult) {
+	targetPath := filepath.Join(opts.RepoRoot, stored.RepoRelativePath)
+	if info, err := os.Lstat(targetPath); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		result.Skipped = true
+		result.SkipReason = "not a symlink"
+		result.Warning = fmt.Sprintf("Skipping %s: not a symlink, use eject or remove the file first",
+			stored.RepoRelativePath)
+		return
+	}
+
+	// Find the links before the stored file moves, while they still resolve
+	var links []string
+	for _, root := range knownClones(opts.RepoRoot, opts.Manifest) {
+		linkPath := filepath.Join(root, stored.RepoRelativePath)
+		if correct, _ := pointsTo(linkPath, stored.StoragePath); correct {
+			links = append(links, linkPath)
+		}
+	}
+
+	trashPath, err := opts.PathConverter.MoveToTrash(stored.StorageFilename)
+	if err != nil && trashPath == "" {
+		result.Skipped = true
+		result.SkipReason = "failed to move to trash"
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: %v", stored.RepoRelativePath, err)
+		return
+	}
+	if err != nil {
+		result.Warning = fmt.Sprintf("%s: %v", stored.RepoRelativePath, err)
+	}
+	result.TrashPath = trashPath
+
+	for _, link := range links {
+		if err := os.Remove(link); err != nil {
+			result.Warning = fmt.Sprintf("failed to remove %s: %v", link, err)
+			continue
+		}
+		result.RemovedLinks = append(result.RemovedLinks, link)
+	}
+
+	opts.Manifest.Remove(stored.StorageFilename)
+	opts.Manifest.RemovePending(stored.RepoRelativePath)
+	result.Success = true
+}
@@ -0,0 +1,114 @@
+package operations
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// FilterName is the name of the git filter driver that keeps personal blocks
+// out of commits, as used in filter.<name>.* config and filter=<name> attributes
+const FilterName = "claude-md"
+
+const (
+	// PersonalStart and PersonalEnd mark the personal block of a committed file.
+	// They are HTML comments so rendered markdown does not show them.
+	PersonalStart = "<!-- >>> claude-md personal >>> -->"
+	PersonalEnd   = "<!-- <<< claude-md personal <<< -->"
+)
+
+// CleanContent removes the personal block from content, returning the team
+// content git should see and the text between the markers. found reports
+// whether content had a block, since an empty block is still a block.
+func CleanContent(content []byte) (clean, block []byte, found bool) {
+	start := bytes.Index(content, []byte(PersonalStart))
+	if start < 0 {
+		return content, nil, false
+	}
+
+	bodyStart := start + len(PersonalStart)
+	if bodyStart < len(content) && content[bodyStart] == '\n' {
+		bodyStart++
+	}
+	end := bytes.Index(content[bodyStart:], []byte(PersonalEnd))
+	if end < 0 {
+		// Without an end marker the rest of the file is personal
+		end = len(content) - bodyStart
+	}
+	block = append([]byte{}, content[bodyStart:bodyStart+end]...)
+
+	rest := content[min(bodyStart+end+len(PersonalEnd), len(content)):]
+	rest = bytes.TrimPrefix(rest, []byte("\n"))
+	before := content[:start]
+	// Drop the blank line SmudgeContent puts before a block at the end of the
+	// file. Elsewhere the newline before the block joins before and rest.
+	if len(rest) == 0 && bytes.HasSuffix(before, []byte("\n\n")) {
+		before = before[:len(before)-1]
+	}
+
+	clean = append(append([]byte{}, before...), rest...)
+	return clean, block, true
+}
+
+// SmudgeContent appends block to content between the personal markers,
+// replacing any block content already has. An empty block leaves the team
+// content alone. CleanContent gives back content unchanged when it ends with
+// a newline, as committed files almost always do.
+func SmudgeContent(content, block []byte) []byte {
+	content, _, _ = CleanContent(content)
+	if len(block) == 0 {
+		return content
+	}
+
+	var b bytes.Buffer
+	b.Write(content)
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		b.WriteString("\n")
+	}
+	if len(content) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(PersonalStart + "\n")
+	b.Write(block)
+	if !bytes.HasSuffix(block, []byte("\n")) {
+		b.WriteString("\n")
+	}
+	b.WriteString(PersonalEnd + "\n")
+	return b.Bytes()
+}
+
+// WriteAttributesBlock replaces the claude-md block of a gitattributes file
+// with a line assigning the filter to each file name pattern, removing the
+// block when patterns is empty. Returns whether the file changed.
+func WriteAttributesBlock(attributesPath string, patterns []string) (bool, error) {
+	var lines []string
+	for _, pattern := range patterns {
+		lines = append(lines, foldCase(pattern)+" filter="+FilterName)
+	}
+	return writeBlock(attributesPath, "# Files filtered by claude-md, updated automatically", lines)
+}
+
+// foldCase turns a file name glob into one git matches regardless of case, as
+// claude-md does, by spelling each letter as a bracket expression
+func foldCase(pattern string) string {
+	var b strings.Builder
+	inBracket := false
+	for _, r := range pattern {
+		switch {
+		case r == '[':
+			inBracket = true
+		case r == ']':
+			inBracket = false
+		case !inBracket && unicode.IsLetter(r) && unicode.ToLower(r) != unicode.ToUpper(r):
+			b.WriteString("[" + string(unicode.ToUpper(r)) + string(unicode.ToLower(r)) + "]")
+			continue
+		}
+		b.WriteRune(r)
+	}
+	folded := b.String()
+	// Patterns with spaces are quoted C style
+	if strings.ContainsAny(folded, " \t\"") {
+		folded = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(folded) + `"`
+	}
+	return folded
+}
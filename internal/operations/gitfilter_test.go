@@ -0,0 +1,126 @@
+package operations_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanSmudgeContent(t *testing.T) {
+	const (
+		start = "<!-- >>> claude-md personal >>> -->\n"
+		end   = "<!-- <<< claude-md personal <<< -->\n"
+	)
+
+	for _, test := range []struct {
+		name     string
+		content  string
+		block    string
+		smudged  string
+		expected string // Block CleanContent gives back
+	}{
+		{
+			name:     "AppendsBlock",
+			content:  "team\n",
+			block:    "mine\n",
+			smudged:  "team\n\n" + start + "mine\n" + end,
+			expected: "mine\n",
+		},
+		{
+			name:     "NoBlock",
+			content:  "team\n",
+			smudged:  "team\n",
+			expected: "",
+		},
+		{
+			name:     "TrailingBlankLine",
+			content:  "team\n\n",
+			block:    "mine\n",
+			smudged:  "team\n\n\n" + start + "mine\n" + end,
+			expected: "mine\n",
+		},
+		{
+			name:     "EmptyFile",
+			block:    "mine",
+			smudged:  start + "mine\n" + end,
+			expected: "mine\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			smudged := operations.SmudgeContent([]byte(test.content), []byte(test.block))
+			assert.Equal(t, test.smudged, string(smudged))
+
+			clean, block, found := operations.CleanContent(smudged)
+			assert.Equal(t, test.content, string(clean))
+			assert.Equal(t, test.expected, string(block))
+			assert.Equal(t, test.block != "", found)
+		})
+	}
+
+	// Git cleans what the user wrote, then smudges the result on checkout
+	t.Run("CleanThenSmudge", func(t *testing.T) {
+		for _, content := range []string{
+			"team\n\n" + start + "mine\n" + end,
+			"team\n\n\n" + start + "mine\n" + end,
+		} {
+			clean, block, found := operations.CleanContent([]byte(content))
+			require.True(t, found)
+			assert.Equal(t, content, string(operations.SmudgeContent(clean, block)))
+		}
+	})
+
+	// A file without a trailing newline gets one, the newline cannot be told
+	// apart from the blank line before the block
+	t.Run("NoTrailingNewline", func(t *testing.T) {
+		smudged := operations.SmudgeContent([]byte("team"), []byte("mine\n"))
+		assert.Equal(t, "team\n\n"+start+"mine\n"+end, string(smudged))
+		clean, _, _ := operations.CleanContent(smudged)
+		assert.Equal(t, "team\n", string(clean))
+	})
+
+	t.Run("NoBlankLineBeforeBlock", func(t *testing.T) {
+		clean, block, found := operations.CleanContent([]byte("team\n" + start + "mine\n" + end))
+		assert.True(t, found)
+		assert.Equal(t, "team\n", string(clean))
+		assert.Equal(t, "mine\n", string(block))
+	})
+
+	t.Run("MidFileBlock", func(t *testing.T) {
+		clean, block, found := operations.CleanContent([]byte("team\n" + start + "mine\n" + end + "more\n"))
+		assert.True(t, found)
+		assert.Equal(t, "team\nmore\n", string(clean))
+		assert.Equal(t, "mine\n", string(block))
+	})
+
+	t.Run("TeamContentAfterBlock", func(t *testing.T) {
+		clean, block, found := operations.CleanContent([]byte("team\n\n" + start + "mine\n" + end + "more\n"))
+		assert.True(t, found)
+		assert.Equal(t, "team\n\nmore\n", string(clean))
+		assert.Equal(t, "mine\n", string(block))
+	})
+}
+
+func TestWriteAttributesBlock(t *testing.T) {
+	attributesPath := filepath.Join(t.TempDir(), "info", "attributes")
+
+	changed, err := operations.WriteAttributesBlock(attributesPath, []string{"CLAUDE.md", "AGENTS *.md"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	content, err := os.ReadFile(attributesPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# >>> claude-md >>>\n# Files filtered by claude-md, updated automatically\n"+
+		"[Cc][Ll][Aa][Uu][Dd][Ee].[Mm][Dd] filter=claude-md\n"+
+		"\"[Aa][Gg][Ee][Nn][Tt][Ss] *.[Mm][Dd]\" filter=claude-md\n"+
+		"# <<< claude-md <<<\n", string(content))
+
+	changed, err = operations.WriteAttributesBlock(attributesPath, nil)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	content, err = os.ReadFile(attributesPath)
+	require.NoError(t, err)
+	assert.Empty(t, string(content))
+}
@@ -0,0 +1,284 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// HookNames are the git hooks claude-md installs: three that restore links after
+// git changed the working tree, and pre-commit, which refuses to commit them
+var HookNames = []string{"post-checkout", "post-merge", "post-rewrite", "pre-commit"}
+
+const (
+	// blockStart and blockEnd mark the lines claude-md owns in files it shares
+	// with the user, such as git hooks and excludes files
+	blockStart = "# >>> claude-md >>>"
+	blockEnd   = "# <<< claude-md <<<"
+	// chainedSuffix is appended to a hook that is not a shell script when it is
+	// moved aside to make room for a wrapper that runs it
+	chainedSuffix = ".claude-md-chained"
+)
+
+// hookBlock returns the claude-md block of a hook. The restore hooks run a quiet
+// restore without consuming the hook's stdin, which post-rewrite passes on to
+// the chained hook, and never fail. pre-commit fails the commit when
+// check-staged finds a problem.
+func hookBlock(name string) string {
+	command := "claude-md restore --quiet </dev/null || true"
+	if name == "pre-commit" {
+		command = "claude-md check-staged </dev/null || exit 1"
+	}
+	return blockStart + `
+# Added by 'claude-md hooks install', remove with 'claude-md hooks uninstall'
+if command -v claude-md >/dev/null 2>&1; then
+	` + command + `
+fi
+` + blockEnd + "\n"
+}
+
+// HookState describes whether a git hook runs claude-md
+type HookState string
+
+const (
+	HookInstalled    HookState = "installed"     // The hook runs claude-md
+	HookNotInstalled HookState = "not installed" // The hook is missing or does not run claude-md
+)
+
+// HookResult represents the result of installing, uninstalling or inspecting a hook
+type HookResult struct {
+	Name    string
+	Path    string
+	State   HookState // State after the operation
+	Changed bool      // The hook file was written or removed
+	Chained bool      // An existing hook runs alongside claude-md
+	Warning string
+	Error   error
+}
+
+// InstallHooks adds the claude-md block to each hook in hooksDir. An existing
+// shell hook gets the block after its #! line, so its own commands and exit
+// status are untouched; any other hook is moved aside and run by a shell wrapper.
+func InstallHooks(hooksDir string) []HookResult {
+	var results []HookResult
+
+	for _, name := range HookNames {
+		result := HookResult{Name: name, Path: filepath.Join(hooksDir, name)}
+		if err := installHook(&result); err != nil {
+			result.Error = err
+			result.Warning = fmt.Sprintf("Skipping %s: %v", result.Path, err)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func installHook(result *HookResult) error {
+	if err := os.MkdirAll(filepath.Dir(result.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	existing, mode, err := readHook(result.Path)
+	if err != nil {
+		return err
+	}
+	_, chainedErr := os.Lstat(result.Path + chainedSuffix)
+	result.Chained = chainedErr == nil
+
+	var content string
+	switch {
+	case existing == "":
+		content = "#!/bin/sh\n" + hookBlock(result.Name)
+		mode = 0755
+	case strings.Contains(existing, blockStart):
+		// Installed before, refresh the block in case it changed
+		content = insertHookBlock(removeBlock(existing), result.Name, result.Chained)
+		result.Chained = result.Chained || hasCommands(removeBlock(existing))
+	case isShellScript(existing):
+		content = insertHookBlock(existing, result.Name, false)
+		result.Chained = true
+	default:
+		// The hook moved aside by an earlier install would be lost
+		if result.Chained {
+			return fmt.Errorf("%s already exists, merge the two hooks by hand", result.Path+chainedSuffix)
+		}
+		if err := os.Rename(result.Path, result.Path+chainedSuffix); err != nil {
+			return fmt.Errorf("failed to move existing hook aside: %w", err)
+		}
+		content = "#!/bin/sh\n" + chainHookBlock(result.Name)
+		result.Chained = true
+	}
+
+	result.State = HookInstalled
+	if mode&0111 == 0 {
+		result.Warning = fmt.Sprintf("%s is not executable, git will not run it", result.Path)
+	}
+	if content == existing {
+		return nil
+	}
+	if err := storage.WriteAtomic(result.Path, []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+	result.Changed = true
+	return nil
+}
+
+// UninstallHooks removes the claude-md block from each hook in hooksDir. A hook
+// left with nothing to run is deleted, and a hook moved aside by InstallHooks
+// is put back.
+func UninstallHooks(hooksDir string) []HookResult {
+	var results []HookResult
+
+	for _, name := range HookNames {
+		result := HookResult{Name: name, Path: filepath.Join(hooksDir, name), State: HookNotInstalled}
+		if err := uninstallHook(&result); err != nil {
+			result.Error = err
+			result.Warning = fmt.Sprintf("Skipping %s: %v", result.Path, err)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func uninstallHook(result *HookResult) error {
+	existing, mode, err := readHook(result.Path)
+	if err != nil || !strings.Contains(existing, blockStart) {
+		return err
+	}
+
+	content := removeBlock(existing)
+	chainedPath := result.Path + chainedSuffix
+	_, chainedErr := os.Lstat(chainedPath)
+	result.Chained = chainedErr == nil || hasCommands(content)
+
+	if !hasCommands(content) {
+		if err := os.Remove(result.Path); err != nil {
+			return fmt.Errorf("failed to remove hook: %w", err)
+		}
+		if chainedErr == nil {
+			if err := os.Rename(chainedPath, result.Path); err != nil {
+				return fmt.Errorf("failed to put back %s: %w", chainedPath, err)
+			}
+		}
+	} else if err := storage.WriteAtomic(result.Path, []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	result.Changed = true
+	return nil
+}
+
+// HookStatus reports whether each hook in hooksDir runs claude-md
+func HookStatus(hooksDir string) []HookResult {
+	var results []HookResult
+
+	for _, name := range HookNames {
+		result := HookResult{Name: name, Path: filepath.Join(hooksDir, name), State: HookNotInstalled}
+		existing, mode, err := readHook(result.Path)
+		if err != nil {
+			result.Error = err
+			result.Warning = fmt.Sprintf("Skipping %s: %v", result.Path, err)
+		} else if strings.Contains(existing, blockStart) {
+			result.State = HookInstalled
+			_, chainedErr := os.Lstat(result.Path + chainedSuffix)
+			result.Chained = chainedErr == nil || hasCommands(removeBlock(existing))
+			if mode&0111 == 0 {
+				result.Warning = fmt.Sprintf("%s is not executable, git will not run it", result.Path)
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// readHook returns the content and mode of a hook, or "" if it does not exist
+func readHook(path string) (string, os.FileMode, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", 0, nil
+		}
+		return "", 0, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read hook: %w", err)
+	}
+	return string(content), info.Mode().Perm(), nil
+}
+
+// isShellScript reports whether a hook is run by a POSIX style shell, so the
+// claude-md block can be added to it. Git runs hooks without #! with sh.
+func isShellScript(content string) bool {
+	if !strings.HasPrefix(content, "#!") {
+		return true
+	}
+	fields := strings.Fields(strings.SplitN(content[2:], "\n", 2)[0])
+	if len(fields) == 0 {
+		return false
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+	switch interpreter {
+	case "sh", "bash", "dash", "ksh", "zsh":
+		return true
+	}
+	return false
+}
+
+// insertHookBlock adds the claude-md block after the #! line of a shell hook
+func insertHookBlock(content, name string, chained bool) string {
+	block := hookBlock(name)
+	if chained {
+		block = chainHookBlock(name)
+	}
+	if !strings.HasPrefix(content, "#!") {
+		return block + content
+	}
+	firstLine, rest, _ := strings.Cut(content, "\n")
+	return firstLine + "\n" + block + rest
+}
+
+// chainHookBlock is the block of a wrapper that runs a hook moved aside,
+// passing on its arguments, stdin and exit status
+func chainHookBlock(name string) string {
+	return strings.TrimSuffix(hookBlock(name), blockEnd+"\n") +
+		`exec "$0` + chainedSuffix + `" "$@"` + "\n" + blockEnd + "\n"
+}
+
+// removeBlock returns content without its claude-md block
+func removeBlock(content string) string {
+	start := strings.Index(content, blockStart)
+	if start < 0 {
+		return content
+	}
+	end := strings.Index(content[start:], blockEnd)
+	if end < 0 {
+		return content[:start]
+	}
+	end += start + len(blockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start] + content[end:]
+}
+
+// hasCommands reports whether a hook has anything besides its #! line,
+// comments and blank lines
+func hasCommands(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
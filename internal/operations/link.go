@@ -0,0 +1,136 @@
+package operations
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// linkModeFor returns override if set, else the mode recorded for storageName, else symlink
+func linkModeFor(manifest *storage.Manifest, storageName string, override storage.LinkMode) storage.LinkMode {
+	if override != "" {
+		return override
+	}
+	if manifest != nil {
+		return manifest.LinkModeFor(storageName)
+	}
+	return storage.LinkSymlink
+}
+
+// placeFile puts the stored file at targetPath, which must not exist
+func placeFile(absStoragePath, targetPath string, mode storage.LinkMode, style storage.LinkStyle) error {
+	switch mode {
+	case storage.LinkHardlink:
+		return os.Link(absStoragePath, targetPath)
+	case storage.LinkCopy:
+		content, err := os.ReadFile(absStoragePath)
+		if err != nil {
+			return err
+		}
+		meta, err := storage.ReadMeta(absStoragePath)
+		if err != nil {
+			return err
+		}
+		f, err := os.OpenFile(targetPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, meta.Mode)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(content); err != nil {
+			_ = f.Close()
+			_ = os.Remove(targetPath)
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		// The copy keeps the stored file's mode and mtime, as a symlink would show them
+		return storage.ApplyMeta(targetPath, meta)
+	default:
+		target, err := symlinkTarget(absStoragePath, targetPath, style)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, targetPath)
+	}
+}
+
+// symlinkTarget returns what a symlink at linkPath should contain to point at absStoragePath
+func symlinkTarget(absStoragePath, linkPath string, style storage.LinkStyle) (string, error) {
+	if style != storage.LinkRelative {
+		return absStoragePath, nil
+	}
+
+	absLinkPath, err := filepath.Abs(linkPath)
+	if err != nil {
+		return "", err
+	}
+	// The OS resolves a relative target from the physical directory of the link
+	return filepath.Rel(files.CanonicalPath(filepath.Dir(absLinkPath)), files.CanonicalPath(absStoragePath))
+}
+
+// linkStyleFor returns override if set, else the repo link style, else absolute
+func linkStyleFor(manifest *storage.Manifest, override storage.LinkStyle) storage.LinkStyle {
+	if override != "" {
+		return override
+	}
+	if manifest != nil {
+		return manifest.GetLinkStyle()
+	}
+	return storage.LinkAbsolute
+}
+
+// pointsTo reports whether the symlink at linkPath refers to storagePath,
+// however the target is spelled
+func pointsTo(linkPath, storagePath string) (bool, error) {
+	target, err := files.ReadLinkTarget(linkPath)
+	if err != nil {
+		return false, err
+	}
+	return files.CanonicalPath(target) == files.CanonicalPath(storagePath), nil
+}
+
+// wasLinkedFrom reports whether linkPath holds the relative symlink that was
+// created at fromPath, which no longer resolves once it is moved to another depth
+func wasLinkedFrom(linkPath, fromPath, storagePath string) bool {
+	raw, err := os.Readlink(linkPath)
+	if err != nil || filepath.IsAbs(raw) {
+		return false
+	}
+	absStoragePath, err := filepath.Abs(storagePath)
+	if err != nil {
+		return false
+	}
+	target, err := symlinkTarget(absStoragePath, fromPath, storage.LinkRelative)
+	return err == nil && raw == target
+}
+
+// isPlacedCopy reports whether the regular file at targetPath is an up to date
+// hardlink or copy of the stored file
+func isPlacedCopy(storagePath, targetPath string, mode storage.LinkMode) (bool, error) {
+	switch mode {
+	case storage.LinkHardlink:
+		targetInfo, err := os.Stat(targetPath)
+		if err != nil {
+			return false, err
+		}
+		storedInfo, err := os.Stat(storagePath)
+		if err != nil {
+			return false, err
+		}
+		return os.SameFile(targetInfo, storedInfo), nil
+	case storage.LinkCopy:
+		content, err := os.ReadFile(targetPath)
+		if err != nil {
+			return false, err
+		}
+		stored, err := os.ReadFile(storagePath)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(content, stored), nil
+	}
+	return false, nil
+}
@@ -0,0 +1,214 @@
+package operations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// MoveResult represents the result of relocating a stored file
+type MoveResult struct {
+	OldPath  string
+	NewPath  string
+	Linked   []string // Absolute paths now linked at the new location, across all clones
+	Removed  []string // Absolute paths of stale links removed at the old location
+	Warnings []string
+}
+
+// MoveOptions contains options for move operation
+type MoveOptions struct {
+	RepoRoot      string
+	PathConverter *storage.PathConverter
+	Manifest      *storage.Manifest // Required, the entry is renamed and supplies the known clones
+	OldPath       string            // Repo relative path the file is stored under
+	NewPath       string            // Repo relative path to store it under
+}
+
+// MoveFile renames a stored file, its history and manifest entry so it is
+// restored at NewPath, then moves the link in this clone and every other known
+// clone. A link the user already moved along with its directory is re-pointed
+// in place.
+func MoveFile(opts MoveOptions) (MoveResult, error) {
+	result := MoveResult{OldPath: opts.OldPath, NewPath: opts.NewPath}
+
+	if !files.MatchesPatterns(filepath.Base(opts.NewPath), opts.PathConverter.Patterns) {
+		return result, fmt.Errorf("%s does not match the managed file patterns", opts.NewPath)
+	}
+	oldStoragePath, err := opts.PathConverter.GetStoragePath(opts.OldPath)
+	if err != nil {
+		return result, err
+	}
+	newStoragePath, err := opts.PathConverter.GetStoragePath(opts.NewPath)
+	if err != nil {
+		return result, err
+	}
+	if _, err := os.Stat(oldStoragePath); err != nil {
+		return result, fmt.Errorf("%s is not managed by claude-md", opts.OldPath)
+	}
+	if _, err := os.Stat(newStoragePath); err == nil {
+		return result, fmt.Errorf("storage already has a file for %s", opts.NewPath)
+	}
+
+	oldName := filepath.Base(oldStoragePath)
+	newName := filepath.Base(newStoragePath)
+	mode := opts.Manifest.LinkModeFor(oldName)
+
+	// Refuse before touching storage if this clone has an unrelated file at the destination
+	newTarget := filepath.Join(opts.RepoRoot, opts.NewPath)
+	oldTarget := filepath.Join(opts.RepoRoot, opts.OldPath)
+	if held, err := holdsStored(newTarget, oldStoragePath, mode); err == nil && !held &&
+		!wasLinkedFrom(newTarget, oldTarget, oldStoragePath) {
+		return result, fmt.Errorf("%s already exists and is not linked to storage", opts.NewPath)
+	}
+
+	// Note where each clone holds the file while the links still resolve
+	type placement struct{ root, oldTarget, newTarget string }
+	var placements []placement
+	for _, root := range knownClones(opts.RepoRoot, opts.Manifest) {
+		placements = append(placements, placement{
+			root:      root,
+			oldTarget: filepath.Join(root, opts.OldPath),
+			newTarget: filepath.Join(root, opts.NewPath),
+		})
+	}
+	heldAt := make(map[string]bool)
+	for _, p := range placements {
+		if held, _ := holdsStored(p.oldTarget, oldStoragePath, mode); held {
+			heldAt[p.oldTarget] = true
+		}
+		if held, _ := holdsStored(p.newTarget, oldStoragePath, mode); held ||
+			wasLinkedFrom(p.newTarget, p.oldTarget, oldStoragePath) {
+			heldAt[p.newTarget] = true
+		}
+	}
+
+	if err := os.Rename(oldStoragePath, newStoragePath); err != nil {
+		return result, fmt.Errorf("failed to rename stored file: %w", err)
+	}
+	if err := opts.PathConverter.RenameHistory(oldName, newName); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+	opts.Manifest.Rename(oldName, newName)
+	opts.Manifest.RemovePending(opts.OldPath)
+
+	absNewStoragePath, err := filepath.Abs(newStoragePath)
+	if err != nil {
+		return result, err
+	}
+	style := linkStyleFor(opts.Manifest, "")
+	current := files.CanonicalPath(opts.RepoRoot)
+
+	for _, p := range placements {
+		if !heldAt[p.oldTarget] && !heldAt[p.newTarget] {
+			continue
+		}
+
+		err := relocate(p.oldTarget, p.newTarget, absNewStoragePath, mode, style, heldAt, p.root == current)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", p.root, err))
+			continue
+		}
+		if heldAt[p.oldTarget] && !heldAt[p.newTarget] {
+			result.Removed = append(result.Removed, p.oldTarget)
+		}
+		if _, err := os.Lstat(p.newTarget); err == nil {
+			result.Linked = append(result.Linked, p.newTarget)
+			if mode != storage.LinkSymlink {
+				moveCopyHash(opts.Manifest, newName, p.oldTarget, p.newTarget)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// relocate moves a placed link or copy from oldTarget to newTarget. Missing
+// parent directories are created in the current clone only; other clones just
+// lose their stale link and pick the file up with their next restore.
+func relocate(oldTarget, newTarget, absStoragePath string, mode storage.LinkMode, style storage.LinkStyle,
+	heldAt map[string]bool, isCurrent bool) error {
+
+	if heldAt[newTarget] {
+		// Already moved along with its directory, only a symlink needs re-pointing
+		if mode != storage.LinkSymlink {
+			return nil
+		}
+		tmp := newTarget + ".claude-md.tmp"
+		_ = os.Remove(tmp)
+		if err := placeFile(absStoragePath, tmp, mode, style); err != nil {
+			return err
+		}
+		return os.Rename(tmp, newTarget)
+	}
+
+	if _, err := os.Stat(filepath.Dir(newTarget)); os.IsNotExist(err) {
+		if !isCurrent {
+			if mode == storage.LinkSymlink {
+				return os.Remove(oldTarget)
+			}
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(newTarget), 0755); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Lstat(newTarget); err == nil {
+		return errors.New("destination already exists, left the old link in place")
+	}
+
+	if mode != storage.LinkSymlink {
+		return os.Rename(oldTarget, newTarget)
+	}
+	if err := placeFile(absStoragePath, newTarget, mode, style); err != nil {
+		return err
+	}
+	return os.Remove(oldTarget)
+}
+
+// holdsStored reports whether path is a link or placed copy of the stored file.
+// Returns an error if nothing exists at path.
+func holdsStored(path, storagePath string, mode storage.LinkMode) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return pointsTo(path, storagePath)
+	}
+	if mode == storage.LinkSymlink {
+		return false, nil
+	}
+	return isPlacedCopy(storagePath, path, mode)
+}
+
+// moveCopyHash carries the sync base of a placed copy over to its new path
+func moveCopyHash(manifest *storage.Manifest, storageName, oldTarget, newTarget string) {
+	oldAbs, err := filepath.Abs(oldTarget)
+	if err != nil {
+		return
+	}
+	newAbs, err := filepath.Abs(newTarget)
+	if err != nil {
+		return
+	}
+	if hash := manifest.CopyHash(storageName, oldAbs); hash != "" {
+		manifest.RemoveCopy(storageName, oldAbs)
+		manifest.SetCopyHash(storageName, newAbs, hash)
+	}
+}
+
+// knownClones returns repoRoot followed by the other clones recorded in the manifest
+func knownClones(repoRoot string, manifest *storage.Manifest) []string {
+	current := files.CanonicalPath(repoRoot)
+	roots := []string{current}
+	for _, clone := range manifest.Clones {
+		if clone != current {
+			roots = append(roots, clone)
+		}
+	}
+	return roots
+}
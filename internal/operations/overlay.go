@@ -0,0 +1,118 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+)
+
+// Overlay pairs a file git tracks with the personal companion claude-md
+// manages next to it, so the team content and personal instructions are both
+// loaded without the committed file ever changing
+type Overlay struct {
+	TrackedPath   string // Repo relative path of the committed file
+	CompanionPath string // Repo relative path of the personal file
+	Imported      bool   // The committed file pulls the companion in with an @import line
+}
+
+// FindOverlays returns the companion of each tracked file in claudeFiles: the
+// first markdown file it pulls in with an @import line that lies inside the
+// repository and is not tracked, else <name>.local<ext> next to it.
+// tracked holds the slash separated paths of the files git tracks.
+func FindOverlays(claudeFiles []files.ClaudeFile, repoRoot string, tracked map[string]bool) []Overlay {
+	var overlays []Overlay
+
+	for _, file := range claudeFiles {
+		if !file.Tracked || file.IsSymlink {
+			continue
+		}
+
+		overlay := Overlay{
+			TrackedPath:   file.RepoRelativePath,
+			CompanionPath: filepath.Join(filepath.Dir(file.RepoRelativePath), files.LocalName(filepath.Base(file.RepoRelativePath))),
+		}
+		if content, err := os.ReadFile(file.AbsolutePath); err == nil {
+			for _, imported := range importedPaths(string(content), filepath.Dir(file.AbsolutePath), repoRoot) {
+				if !tracked[filepath.ToSlash(imported)] {
+					overlay.CompanionPath = imported
+					overlay.Imported = true
+					break
+				}
+			}
+		}
+		overlays = append(overlays, overlay)
+	}
+
+	return overlays
+}
+
+// CompanionFiles returns the companions of overlays that exist in the working
+// tree and are missing from claudeFiles, because their names match no pattern
+func CompanionFiles(overlays []Overlay, claudeFiles []files.ClaudeFile, repoRoot string) []files.ClaudeFile {
+	known := make(map[string]bool, len(claudeFiles))
+	for _, file := range claudeFiles {
+		known[file.RepoRelativePath] = true
+	}
+
+	var companions []files.ClaudeFile
+	for _, overlay := range overlays {
+		if known[overlay.CompanionPath] {
+			continue
+		}
+		path := filepath.Join(repoRoot, overlay.CompanionPath)
+		info, err := os.Lstat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		known[overlay.CompanionPath] = true
+		companions = append(companions, files.ClaudeFile{
+			AbsolutePath:     path,
+			RepoRelativePath: overlay.CompanionPath,
+			IsSymlink:        info.Mode()&os.ModeSymlink != 0,
+		})
+	}
+	return companions
+}
+
+// importedPaths returns the repo relative paths of the markdown files content
+// pulls in with @path imports, resolved against dir. Imports in code blocks,
+// outside the repository or under .git are ignored.
+func importedPaths(content, dir, repoRoot string) []string {
+	var paths []string
+	inCode := false
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		for _, word := range strings.Fields(line) {
+			if !strings.HasPrefix(word, "@") || !strings.EqualFold(filepath.Ext(word), ".md") {
+				continue
+			}
+			target := strings.TrimPrefix(word, "@")
+			if strings.HasPrefix(target, "~") {
+				continue
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+			// The companion itself may already be a link into storage
+			target = filepath.Join(files.CanonicalPath(filepath.Dir(target)), filepath.Base(target))
+			rel, err := filepath.Rel(files.CanonicalPath(repoRoot), target)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) ||
+				rel == ".git" || strings.HasPrefix(rel, ".git"+string(filepath.Separator)) {
+				continue
+			}
+			paths = append(paths, rel)
+		}
+	}
+
+	return paths
+}
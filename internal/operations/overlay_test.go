@@ -0,0 +1,52 @@
+package operations_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOverlays(t *testing.T) {
+	repoRoot := t.TempDir()
+
+	for _, test := range []struct {
+		name     string
+		content  string
+		expected operations.Overlay
+	}{
+		{
+			name:     "LocalCompanion",
+			content:  "Team instructions\n",
+			expected: operations.Overlay{TrackedPath: "CLAUDE.md", CompanionPath: "CLAUDE.local.md"},
+		},
+		{
+			name:     "ImportedCompanion",
+			content:  "See @docs/team.md\n@.claude/personal.md\n",
+			expected: operations.Overlay{TrackedPath: "CLAUDE.md", CompanionPath: ".claude/personal.md", Imported: true},
+		},
+		{
+			name:     "IgnoredImports",
+			content:  "```\n@example.md\n```\n@~/.claude/mine.md @../outside.md @.git/notes.md\n",
+			expected: operations.Overlay{TrackedPath: "CLAUDE.md", CompanionPath: "CLAUDE.local.md"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(repoRoot, "CLAUDE.md")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0644))
+
+			claudeFiles := []files.ClaudeFile{
+				{AbsolutePath: path, RepoRelativePath: "CLAUDE.md", Tracked: true},
+				{AbsolutePath: filepath.Join(repoRoot, "docs", "CLAUDE.md"), RepoRelativePath: "docs/CLAUDE.md"},
+			}
+			tracked := map[string]bool{"CLAUDE.md": true, "docs/team.md": true}
+
+			assert.Equal(t, []operations.Overlay{test.expected},
+				operations.FindOverlays(claudeFiles, repoRoot, tracked))
+		})
+	}
+}
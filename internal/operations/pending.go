@@ -0,0 +1,41 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// RestorePending links files in the manifest's pending list whose parent directory now exists.
+// Files that were linked, are already correct, hit another problem, or are no longer in storage
+// are dropped from the list. Files whose directory is still missing stay pending.
+// Returns the results of the restores that were attempted.
+func RestorePending(storedFiles []files.StoredFile, manifest *storage.Manifest, opts RestoreOptions) []RestoreResult {
+	var results []RestoreResult
+
+	byPath := make(map[string]files.StoredFile, len(storedFiles))
+	for _, stored := range storedFiles {
+		byPath[stored.RepoRelativePath] = stored
+	}
+
+	for _, path := range append([]string{}, manifest.Pending...) {
+		stored, ok := byPath[path]
+		if !ok {
+			manifest.RemovePending(path)
+			continue
+		}
+
+		parentDir := filepath.Dir(filepath.Join(opts.RepoRoot, path))
+		if _, err := os.Stat(parentDir); err != nil {
+			continue
+		}
+
+		attempt := RestoreFiles([]files.StoredFile{stored}, opts)
+		manifest.RemovePending(path)
+		results = append(results, attempt...)
+	}
+
+	return results
+}
@@ -0,0 +1,95 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// ReconcileResult reports what one pass over the working tree did
+type ReconcileResult struct {
+	Restored []RestoreResult // Links re-created where a managed file disappeared
+	Saved    []SaveResult    // Regular files that replaced their link, and new files saved with SaveNew
+	Unsaved  []string        // Repo paths of new files left for the user to save
+}
+
+// ReconcileOptions contains options for reconcile operation
+type ReconcileOptions struct {
+	RepoRoot      string
+	PathConverter *storage.PathConverter
+	Manifest      *storage.Manifest
+	SaveNew       bool // Save new CLAUDE.md files instead of only reporting them
+	// Tracked, TrackedPaths and SetSkipWorktree decide whether links are re-created
+	// in place of files git tracks, as for RestoreOptions
+	Tracked         storage.TrackedPolicy
+	TrackedPaths    map[string]bool
+	SetSkipWorktree func(repoRelativePath string, skip bool) error
+}
+
+// Reconcile repairs the working tree after changes made behind claude-md's back:
+// links that disappeared are re-created when their directory still exists, regular
+// files that replaced a link are re-absorbed as save --update would, and new files
+// are reported or, with SaveNew, saved. Moved links and files that differ from a
+// newer stored copy are left for the user.
+func Reconcile(claudeFiles []files.ClaudeFile, storedFiles []files.StoredFile, opts ReconcileOptions) ReconcileResult {
+	var result ReconcileResult
+
+	statuses := Status(claudeFiles, storedFiles, StatusOptions{RepoRoot: opts.RepoRoot, Manifest: opts.Manifest})
+	states := make(map[string]FileState, len(statuses))
+	for _, status := range statuses {
+		states[status.RepoRelativePath] = status.State
+	}
+
+	var missing []files.StoredFile
+	for _, stored := range storedFiles {
+		if states[stored.RepoRelativePath] != StateMissing {
+			continue
+		}
+		// A directory removed by a branch switch takes its link with it
+		parentDir := filepath.Dir(filepath.Join(opts.RepoRoot, stored.RepoRelativePath))
+		if _, err := os.Stat(parentDir); err == nil {
+			missing = append(missing, stored)
+		}
+	}
+	result.Restored = RestoreFiles(missing, RestoreOptions{
+		RepoRoot:        opts.RepoRoot,
+		Manifest:        opts.Manifest,
+		Tracked:         opts.Tracked,
+		TrackedPaths:    opts.TrackedPaths,
+		SetSkipWorktree: opts.SetSkipWorktree,
+	})
+
+	var replaced, unsaved []files.ClaudeFile
+	for _, file := range claudeFiles {
+		switch states[file.RepoRelativePath] {
+		case StateConflict:
+			replaced = append(replaced, file)
+		case StateUnsaved:
+			if opts.SaveNew {
+				unsaved = append(unsaved, file)
+			} else {
+				result.Unsaved = append(result.Unsaved, file.RepoRelativePath)
+			}
+		}
+	}
+
+	saveOpts := SaveOptions{
+		RepoRoot:      opts.RepoRoot,
+		PathConverter: opts.PathConverter,
+		Manifest:      opts.Manifest,
+		OnConflict:    SaveConflictSkip,
+	}
+	result.Saved = SaveFiles(unsaved, saveOpts)
+
+	// Only files newer than (or identical to) storage are re-absorbed, the rest are skipped
+	saveOpts.Update = true
+	for _, saved := range SaveFiles(replaced, saveOpts) {
+		if saved.Updated || saved.Error != nil {
+			result.Saved = append(result.Saved, saved)
+		}
+	}
+
+	return result
+}
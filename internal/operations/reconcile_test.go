@@ -0,0 +1,157 @@
+package operations_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoDir := filepath.Join(tmpDir, "repo")
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "docs"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "gone"), 0755))
+
+	pc := &storage.PathConverter{
+		StorageRoot: filepath.Join(tmpDir, "storage"),
+		RepoName:    "test.git",
+	}
+	manifest, err := pc.LoadManifest()
+	require.NoError(t, err)
+
+	for _, path := range []string{"CLAUDE.md", "docs/CLAUDE.md", "gone/CLAUDE.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, path), []byte("stored "+path), 0644))
+	}
+	claudeFiles, err := files.FindClaudeFiles(repoDir)
+	require.NoError(t, err)
+	for _, result := range operations.SaveFiles(claudeFiles, operations.SaveOptions{
+		RepoRoot:      repoDir,
+		PathConverter: pc,
+		Manifest:      manifest,
+	}) {
+		require.True(t, result.Success, result.Warning)
+	}
+
+	reconcile := func(saveNew bool) operations.ReconcileResult {
+		claudeFiles, err := files.FindClaudeFiles(repoDir)
+		require.NoError(t, err)
+		storedFiles, err := files.FindStoredFiles(pc.GetRepoStorageDir(), pc)
+		require.NoError(t, err)
+		return operations.Reconcile(claudeFiles, storedFiles, operations.ReconcileOptions{
+			RepoRoot:      repoDir,
+			PathConverter: pc,
+			Manifest:      manifest,
+			SaveNew:       saveNew,
+		})
+	}
+
+	// The root link is deleted, the docs link is replaced by an editor, a
+	// directory disappears and a new file turns up
+	require.NoError(t, os.Remove(filepath.Join(repoDir, "CLAUDE.md")))
+	docsFile := filepath.Join(repoDir, "docs", "CLAUDE.md")
+	require.NoError(t, os.Remove(docsFile))
+	require.NoError(t, os.WriteFile(docsFile, []byte("edited"), 0644))
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(docsFile, future, future))
+	require.NoError(t, os.RemoveAll(filepath.Join(repoDir, "gone")))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "new"), 0755))
+	newFile := filepath.Join(repoDir, "new", "CLAUDE.md")
+	require.NoError(t, os.WriteFile(newFile, []byte("new"), 0644))
+
+	result := reconcile(false)
+
+	require.Len(t, result.Restored, 1)
+	assert.Equal(t, "CLAUDE.md", result.Restored[0].RepoRelativePath)
+	assert.True(t, result.Restored[0].Success)
+	info, err := os.Lstat(filepath.Join(repoDir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink)
+
+	require.Len(t, result.Saved, 1)
+	assert.Equal(t, "docs/CLAUDE.md", result.Saved[0].RepoRelativePath)
+	assert.True(t, result.Saved[0].Updated)
+	content, err := os.ReadFile(docsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "edited", string(content))
+	info, err = os.Lstat(docsFile)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink)
+
+	_, err = os.Stat(filepath.Join(repoDir, "gone"))
+	assert.True(t, os.IsNotExist(err), "a removed directory is not re-created")
+
+	assert.Equal(t, []string{"new/CLAUDE.md"}, result.Unsaved)
+	info, err = os.Lstat(newFile)
+	require.NoError(t, err)
+	assert.True(t, info.Mode().IsRegular())
+
+	t.Run("SaveNew", func(t *testing.T) {
+		result := reconcile(true)
+
+		assert.Empty(t, result.Restored)
+		assert.Empty(t, result.Unsaved)
+		require.Len(t, result.Saved, 1)
+		assert.Equal(t, "new/CLAUDE.md", result.Saved[0].RepoRelativePath)
+		assert.True(t, result.Saved[0].Success)
+		info, err := os.Lstat(newFile)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&os.ModeSymlink)
+	})
+
+	t.Run("OlderReplacementIsLeftAlone", func(t *testing.T) {
+		require.NoError(t, os.Remove(docsFile))
+		require.NoError(t, os.WriteFile(docsFile, []byte("older"), 0644))
+		past := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(docsFile, past, past))
+
+		result := reconcile(false)
+
+		assert.Empty(t, result.Saved)
+		content, err := os.ReadFile(docsFile)
+		require.NoError(t, err)
+		assert.Equal(t, "older", string(content))
+	})
+	t.Run("EjectedIsLeftAlone", func(t *testing.T) {
+		claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+		storedFiles, err := files.FindStoredFiles(pc.GetRepoStorageDir(), pc)
+		require.NoError(t, err)
+		var root []files.StoredFile
+		for _, stored := range storedFiles {
+			if stored.RepoRelativePath == "CLAUDE.md" {
+				root = append(root, stored)
+			}
+		}
+		results := operations.EjectFiles(root, operations.EjectOptions{
+			RepoRoot:      repoDir,
+			PathConverter: pc,
+			Manifest:      manifest,
+		})
+		require.Len(t, results, 1)
+		require.True(t, results[0].Success, results[0].Warning)
+
+		// A newer edit would be re-absorbed if the file were still managed
+		require.NoError(t, os.WriteFile(claudeFile, []byte("ejected"), 0644))
+		require.NoError(t, os.Chtimes(claudeFile, future, future))
+		result := reconcile(false)
+		assert.Empty(t, result.Restored)
+		assert.Empty(t, result.Saved)
+		assert.Empty(t, result.Unsaved)
+		info, err := os.Lstat(claudeFile)
+		require.NoError(t, err)
+		assert.True(t, info.Mode().IsRegular())
+
+		// Nor is it linked again once deleted
+		require.NoError(t, os.Remove(claudeFile))
+		result = reconcile(false)
+		assert.Empty(t, result.Restored)
+		_, err = os.Lstat(claudeFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
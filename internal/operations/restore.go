@@ -1,12 +1,14 @@
 package operations
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
 )
 
 // RestoreResult represents the result of restoring a file
@@ -14,15 +16,35 @@ type RestoreResult struct {
 	RepoRelativePath string
 	Success          bool
 	Skipped          bool
-	SkipReason       string // "already exists", "parent dir missing", etc.
-	Warning          string // For parent directory missing case
-	StoragePath      string // Path to stored file (for warnings)
+	SkipReason       string                // "already exists", "parent dir missing", "deferred", etc.
+	Warning          string                // For parent directory missing case
+	StoragePath      string                // Path to stored file (for warnings)
+	Resolution       RestoreConflictPolicy // How a regular file in the way was handled, empty if there was none
+	BackupPath       string                // Where a regular file in the way was moved to
+	CreatedParents   bool                  // Missing parent directories were created
+	Deferred         bool                  // Parent directory is missing, restore once it exists
 	Error            error
 }
 
 // RestoreOptions contains options for restore operation
 type RestoreOptions struct {
-	RepoRoot string
+	RepoRoot      string
+	OnConflict    RestoreConflictPolicy  // What to do when a regular file is in the way, defaults to skip
+	CreateParents bool                   // Create missing parent directories instead of skipping
+	Defer         bool                   // Mark files with a missing parent directory as deferred
+	Manifest      *storage.Manifest      // Supplies link modes and records placed copies when set
+	PathConverter *storage.PathConverter // Where the backup policy keeps replaced files, next to them when unset
+	LinkMode      storage.LinkMode       // How to place files, recorded per file; empty uses the manifest
+	LinkStyle     storage.LinkStyle      // Absolute or relative symlinks; empty uses the manifest
+	Ejected       bool                   // Also restore files ejected from this working tree, managing them again
+	// Tracked decides whether files git tracks, listed by slash separated repo
+	// path in TrackedPaths, are linked. With skip-worktree they are marked
+	// through SetSkipWorktree before the committed file is replaced.
+	Tracked         storage.TrackedPolicy
+	TrackedPaths    map[string]bool
+	SetSkipWorktree func(repoRelativePath string, skip bool) error
+	// Prompt is asked for a policy per file when OnConflict is RestoreConflictPrompt
+	Prompt func(stored files.StoredFile, identical bool) (RestoreConflictPolicy, error)
 }
 
 // RestoreFiles creates symlinks for stored files
@@ -34,6 +56,7 @@ func RestoreFiles(storedFiles []files.StoredFile, opts RestoreOptions) []Restore
 			RepoRelativePath: stored.RepoRelativePath,
 			StoragePath:      stored.StoragePath,
 		}
+		mode := linkModeFor(opts.Manifest, stored.StorageFilename, opts.LinkMode)
 
 		// Construct target path in repo
 		targetPath := filepath.Join(opts.RepoRoot, stored.RepoRelativePath)
@@ -70,9 +93,36 @@ func RestoreFiles(storedFiles []files.StoredFile, opts RestoreOptions) []Restore
 			continue
 		}
 
+		// An ejected file stays a regular file until the user names it again
+		if !opts.Ejected && opts.Manifest != nil && opts.Manifest.IsEjected(stored.StorageFilename, absTargetPath) {
+			result.Skipped = true
+			result.SkipReason = "ejected"
+			results = append(results, result)
+			continue
+		}
+
 		// Check if parent directory exists
 		parentDir := filepath.Dir(targetPath)
-		if _, err := os.Stat(parentDir); os.IsNotExist(err) {
+		if _, err := os.Stat(parentDir); os.IsNotExist(err) && opts.CreateParents {
+			if err := os.MkdirAll(parentDir, 0755); err != nil {
+				result.Skipped = true
+				result.SkipReason = "parent dir creation failed"
+				result.Error = err
+				result.Warning = fmt.Sprintf("Skipping %s: failed to create parent directory: %v",
+					stored.RepoRelativePath, err)
+				results = append(results, result)
+				continue
+			}
+			result.CreatedParents = true
+		} else if os.IsNotExist(err) && opts.Defer {
+			result.Skipped = true
+			result.Deferred = true
+			result.SkipReason = "deferred"
+			result.Warning = fmt.Sprintf("Deferred %s: parent directory does not exist yet, it will be linked once it does",
+				stored.RepoRelativePath)
+			results = append(results, result)
+			continue
+		} else if os.IsNotExist(err) {
 			result.Skipped = true
 			result.SkipReason = "parent dir missing"
 			result.Warning = fmt.Sprintf("Skipping %s: parent directory does not exist (storage: %s)",
@@ -81,12 +131,28 @@ func RestoreFiles(storedFiles []files.StoredFile, opts RestoreOptions) []Restore
 			continue
 		}
 
+		// A link in place of a committed file would be committed as a symlink into $HOME
+		trackedPath := filepath.ToSlash(stored.RepoRelativePath)
+		tracked := opts.TrackedPaths[trackedPath]
+		if tracked && opts.Tracked != storage.TrackedSkipWorktree {
+			if info, err := os.Lstat(targetPath); err != nil || info.Mode()&os.ModeSymlink == 0 {
+				result.Skipped = true
+				result.SkipReason = "tracked by git"
+				result.Warning = fmt.Sprintf("Skipping %s: tracked by git, linking it would commit a symlink "+
+					"into your home directory (see 'claude-md init --tracked')", stored.RepoRelativePath)
+				results = append(results, result)
+				continue
+			}
+		}
+
 		// Check if file already exists at target location
+		var asidePath string
 		if info, err := os.Lstat(targetPath); err == nil {
 			// File exists - check if it's a symlink
 			if info.Mode()&os.ModeSymlink != 0 {
-				// It's a symlink - check if it points to the correct location
-				currentTarget, err := os.Readlink(targetPath)
+				// It's a symlink - check if it points to the correct location.
+				// Both sides are resolved, so relative and absolute links to the same file match.
+				correct, err := pointsTo(targetPath, stored.StoragePath)
 				if err != nil {
 					result.Skipped = true
 					result.SkipReason = "symlink read failed"
@@ -97,19 +163,7 @@ func RestoreFiles(storedFiles []files.StoredFile, opts RestoreOptions) []Restore
 					continue
 				}
 
-				// Get absolute path to storage for comparison
-				absStoragePath, err := filepath.Abs(stored.StoragePath)
-				if err != nil {
-					result.Skipped = true
-					result.SkipReason = "absolute path failed"
-					result.Error = err
-					result.Warning = fmt.Sprintf("Skipping %s: failed to get absolute path: %v",
-						stored.RepoRelativePath, err)
-					results = append(results, result)
-					continue
-				}
-
-				if currentTarget == absStoragePath {
+				if correct {
 					// Already points to correct location
 					result.Skipped = true
 					result.SkipReason = "already correct"
@@ -117,6 +171,7 @@ func RestoreFiles(storedFiles []files.StoredFile, opts RestoreOptions) []Restore
 					continue
 				}
 
+				currentTarget, _ := os.Readlink(targetPath)
 				// Points to wrong location
 				result.Skipped = true
 				result.SkipReason = "wrong target"
@@ -126,18 +181,50 @@ func RestoreFiles(storedFiles []files.StoredFile, opts RestoreOptions) []Restore
 				continue
 			}
 
-			// It's a regular file
-			result.Skipped = true
-			result.SkipReason = "file exists"
-			result.Warning = fmt.Sprintf("Skipping %s: regular file already exists (storage: %s)",
-				stored.RepoRelativePath, stored.StoragePath)
-			results = append(results, result)
-			continue
+			// It may already be the hardlink or copy we would create
+			if mode != storage.LinkSymlink {
+				if placed, _ := isPlacedCopy(stored.StoragePath, targetPath, mode); placed {
+					result.Skipped = true
+					result.SkipReason = "already correct"
+					results = append(results, result)
+					continue
+				}
+			}
+
+			// It's a regular file, move it out of the way if the conflict policy allows
+			aside, ok := resolveRestoreConflict(stored, targetPath, opts, &result)
+			if !ok {
+				results = append(results, result)
+				continue
+			}
+			asidePath = aside
+		}
+
+		// Git must ignore the committed file before it is replaced
+		marked := false
+		if tracked && opts.SetSkipWorktree != nil {
+			if err := opts.SetSkipWorktree(trackedPath, true); err != nil {
+				putBack(asidePath, targetPath, &result)
+				result.Skipped = true
+				result.SkipReason = "skip-worktree failed"
+				result.Error = err
+				result.Warning = fmt.Sprintf("Skipping %s: %v", stored.RepoRelativePath, err)
+				results = append(results, result)
+				continue
+			}
+			marked = true
+		}
+		unmark := func() {
+			if marked {
+				_ = opts.SetSkipWorktree(trackedPath, false)
+			}
 		}
 
 		// Get absolute storage path for symlink
 		absStoragePath, err := filepath.Abs(stored.StoragePath)
 		if err != nil {
+			putBack(asidePath, targetPath, &result)
+			unmark()
 			result.Skipped = true
 			result.SkipReason = "absolute path failed"
 			result.Error = err
@@ -147,8 +234,10 @@ func RestoreFiles(storedFiles []files.StoredFile, opts RestoreOptions) []Restore
 			continue
 		}
 
-		// Create symlink
-		if err := os.Symlink(absStoragePath, targetPath); err != nil {
+		// Create symlink, hardlink or copy
+		if err := placeFile(absStoragePath, targetPath, mode, linkStyleFor(opts.Manifest, opts.LinkStyle)); err != nil {
+			putBack(asidePath, targetPath, &result)
+			unmark()
 			result.Skipped = true
 			result.SkipReason = "symlink creation failed"
 			result.Error = err
@@ -158,9 +247,131 @@ func RestoreFiles(storedFiles []files.StoredFile, opts RestoreOptions) []Restore
 			continue
 		}
 
+		// The file we moved aside is only kept when the user asked for a backup
+		if asidePath != "" {
+			if result.Resolution != RestoreConflictBackup {
+				_ = os.Remove(asidePath)
+			} else if opts.PathConverter != nil {
+				backupAside(stored, asidePath, opts.PathConverter, &result)
+			}
+		}
+
+		if opts.Manifest != nil {
+			opts.Manifest.SetEjected(stored.StorageFilename, absTargetPath, false)
+			if opts.LinkMode != "" {
+				opts.Manifest.SetLinkMode(stored.StorageFilename, opts.LinkMode)
+			}
+			if mode != storage.LinkSymlink {
+				if content, err := os.ReadFile(stored.StoragePath); err == nil {
+					opts.Manifest.SetCopyHash(stored.StorageFilename, absTargetPath, storage.HashContent(content))
+				}
+			}
+		}
+
 		result.Success = true
 		results = append(results, result)
 	}
 
 	return results
 }
+
+// resolveRestoreConflict applies the conflict policy to a regular file at targetPath.
+// When the policy allows linking, the file is renamed aside and its new path returned.
+func resolveRestoreConflict(stored files.StoredFile, targetPath string, opts RestoreOptions,
+	result *RestoreResult) (string, bool) {
+
+	skip := func(reason, warning string, err error) (string, bool) {
+		result.Skipped = true
+		result.SkipReason = reason
+		result.Error = err
+		result.Warning = warning
+		return "", false
+	}
+
+	exists := func() (string, bool) {
+		return skip("file exists", fmt.Sprintf("Skipping %s: regular file already exists (storage: %s)",
+			stored.RepoRelativePath, stored.StoragePath), nil)
+	}
+
+	policy := opts.OnConflict
+	if policy == "" || policy == RestoreConflictSkip {
+		return exists()
+	}
+
+	existing, err := os.ReadFile(targetPath)
+	if err != nil {
+		return skip("read failed", fmt.Sprintf("Skipping %s: failed to read existing file: %v",
+			stored.RepoRelativePath, err), err)
+	}
+	storedContent, err := os.ReadFile(stored.StoragePath)
+	if err != nil {
+		return skip("read failed", fmt.Sprintf("Skipping %s: failed to read stored file: %v",
+			stored.RepoRelativePath, err), err)
+	}
+	identical := bytes.Equal(existing, storedContent)
+
+	if policy == RestoreConflictPrompt {
+		policy = RestoreConflictSkip
+		if opts.Prompt != nil {
+			p, err := opts.Prompt(stored, identical)
+			if err != nil {
+				return skip("prompt failed", fmt.Sprintf("Skipping %s: prompt failed: %v",
+					stored.RepoRelativePath, err), err)
+			}
+			policy = p
+		}
+	}
+
+	switch {
+	case policy == RestoreConflictAdoptIdentical && identical,
+		policy == RestoreConflictReplace,
+		policy == RestoreConflictBackup:
+	case policy == RestoreConflictAdoptIdentical:
+		return skip("file differs", fmt.Sprintf("Skipping %s: regular file differs from storage (storage: %s)",
+			stored.RepoRelativePath, stored.StoragePath), nil)
+	default:
+		return exists()
+	}
+
+	// Rename rather than delete so the file can be put back if linking fails
+	aside := targetPath + "." + storage.Timestamp() + ".bak"
+	if err := os.Rename(targetPath, aside); err != nil {
+		return skip("move aside failed", fmt.Sprintf("Skipping %s: failed to move existing file aside: %v",
+			stored.RepoRelativePath, err), err)
+	}
+
+	result.Resolution = policy
+	if policy == RestoreConflictBackup {
+		result.BackupPath = aside
+	}
+	return aside, true
+}
+
+// backupAside moves a file moved aside by the backup policy into storage's
+// .backups, out of the working tree. The file stays where it is if that fails.
+func backupAside(stored files.StoredFile, asidePath string, pc *storage.PathConverter, result *RestoreResult) {
+	content, err := os.ReadFile(asidePath)
+	if err == nil {
+		var backupPath string
+		if backupPath, err = pc.WriteBackup(stored.StorageFilename, content); err == nil {
+			_ = os.Remove(asidePath)
+			result.BackupPath = backupPath
+			return
+		}
+	}
+	result.Warning = fmt.Sprintf("%s: the replaced file is at %s, it could not be moved to storage: %v",
+		stored.RepoRelativePath, asidePath, err)
+}
+
+// putBack returns a file moved aside by resolveRestoreConflict to its original location
+func putBack(asidePath, targetPath string, result *RestoreResult) {
+	if asidePath == "" {
+		return
+	}
+	if err := os.Rename(asidePath, targetPath); err != nil {
+		result.Warning = fmt.Sprintf("CRITICAL: %s could not be moved back, it is at %s: %v",
+			result.RepoRelativePath, asidePath, err)
+	}
+	result.Resolution = ""
+	result.BackupPath = ""
+}
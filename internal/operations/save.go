@@ -1,11 +1,13 @@
 package operations
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/merge"
 	"github.com/kapetan-io/claude-md.go/internal/storage"
 )
 
@@ -15,8 +17,12 @@ type SaveResult struct {
 	StoragePath      string // Path to storage file (for warnings/info)
 	Success          bool
 	Skipped          bool
-	SkipReason       string // "already symlink", "invalid path", "storage file exists", etc.
-	Warning          string // Warning message for user
+	SkipReason       string             // "already symlink", "invalid path", "storage file exists", etc.
+	Warning          string             // Warning message for user
+	Resolution       SaveConflictPolicy // How an existing storage file was handled, empty if there was none
+	BackupPath       string             // Replaced storage content (overwrite) or the suffixed copy (keep-both)
+	Conflicts        int                // Conflict hunks left in storage by a merge
+	Updated          bool               // Storage was refreshed from a regular file that replaced the symlink
 	Error            error
 }
 
@@ -24,6 +30,19 @@ type SaveResult struct {
 type SaveOptions struct {
 	RepoRoot      string
 	PathConverter *storage.PathConverter
+	Manifest      *storage.Manifest  // When set, records the saved version of each file
+	OnConflict    SaveConflictPolicy // What to do when storage already has the file, defaults to skip
+	Update        bool               // Re-absorb regular files that are newer than (or identical to) their stored copy
+	Ejected       bool               // Also save files ejected from this working tree, managing them again
+	LinkMode      storage.LinkMode   // How to place saved files, recorded per file; empty uses the manifest
+	LinkStyle     storage.LinkStyle  // Absolute or relative symlinks; empty uses the manifest
+	// Tracked decides whether files git tracks are linked. Setting skip-worktree
+	// on the ones saved is left to the caller.
+	Tracked storage.TrackedPolicy
+	// Companions names the personal companion of tracked files, by repo path, when Tracked is local
+	Companions map[string]string
+	// Prompt is asked for a policy per file when OnConflict is SaveConflictPrompt
+	Prompt func(file files.ClaudeFile, storagePath string) (SaveConflictPolicy, error)
 }
 
 // SaveFiles converts CLAUDE.md files to symlinks
@@ -31,159 +50,444 @@ func SaveFiles(claudeFiles []files.ClaudeFile, opts SaveOptions) []SaveResult {
 	var results []SaveResult
 
 	for _, file := range claudeFiles {
-		result := SaveResult{
-			RepoRelativePath: file.RepoRelativePath,
+		results = append(results, saveFile(file, opts))
+	}
+
+	return results
+}
+
+func saveFile(file files.ClaudeFile, opts SaveOptions) SaveResult {
+	result := SaveResult{
+		RepoRelativePath: file.RepoRelativePath,
+	}
+
+	// Validate path
+	if err := storage.ValidatePath(file.RepoRelativePath); err != nil {
+		result.Skipped = true
+		result.SkipReason = "invalid path"
+		result.Warning = fmt.Sprintf("Skipping %s: %v", file.RepoRelativePath, err)
+		result.Error = err
+		return result
+	}
+
+	// Skip if already a symlink
+	if file.IsSymlink {
+		result.Skipped = true
+		result.SkipReason = "already symlink"
+		return result
+	}
+
+	// A link in place of a committed file would be committed as a symlink into $HOME
+	if file.Tracked && opts.Tracked != storage.TrackedSkipWorktree {
+		result.Skipped = true
+		result.SkipReason = "tracked by git"
+		if opts.Tracked == storage.TrackedLocal {
+			companion, ok := opts.Companions[file.RepoRelativePath]
+			if !ok {
+				companion = filepath.Join(filepath.Dir(file.RepoRelativePath), files.LocalName(filepath.Base(file.RepoRelativePath)))
+			}
+			result.Warning = fmt.Sprintf("Skipping %s: tracked by git, keep personal instructions in %s",
+				file.RepoRelativePath, companion)
+		} else {
+			result.Warning = fmt.Sprintf("Skipping %s: tracked by git, linking it would commit a symlink "+
+				"into your home directory (see 'claude-md init --tracked')", file.RepoRelativePath)
 		}
+		return result
+	}
+
+	// Get storage path
+	storagePath, err := opts.PathConverter.GetStoragePath(file.RepoRelativePath)
+	if err != nil {
+		result.Skipped = true
+		result.SkipReason = "path conversion error"
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: %v", file.RepoRelativePath, err)
+		return result
+	}
+	result.StoragePath = storagePath
+	storageName := filepath.Base(storagePath)
+	mode := linkModeFor(opts.Manifest, storageName, opts.LinkMode)
 
-		// Validate path
-		if err := storage.ValidatePath(file.RepoRelativePath); err != nil {
-			result.Skipped = true
-			result.SkipReason = "invalid path"
-			result.Warning = fmt.Sprintf("Skipping %s: %v", file.RepoRelativePath, err)
-			result.Error = err
-			results = append(results, result)
-			continue
+	// An ejected file stays a regular file until the user names it again
+	if !opts.Ejected && ejected(opts.Manifest, storageName, file.AbsolutePath) {
+		result.Skipped = true
+		result.SkipReason = "ejected"
+		return result
+	}
+
+	// Hardlinks and copies are regular files, so they look like unsaved files here.
+	// Changes to them are reconciled by sync rather than by the conflict policies,
+	// asking for another link mode places them again.
+	if opts.Manifest != nil && opts.Manifest.LinkModeFor(storageName) != storage.LinkSymlink {
+		if _, ok := opts.Manifest.Files[storageName]; ok {
+			if _, err := os.Stat(storagePath); err == nil {
+				if opts.LinkMode != "" && opts.LinkMode != opts.Manifest.LinkModeFor(storageName) {
+					replaceCopy(file, storagePath, opts, &result)
+					return result
+				}
+				result.Skipped = true
+				result.SkipReason = "managed copy"
+				return result
+			}
 		}
+	}
+
+	// Ensure storage directory exists
+	if err := opts.PathConverter.EnsureStorageDir(); err != nil {
+		result.Skipped = true
+		result.SkipReason = "storage directory creation failed"
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: failed to create storage directory: %v",
+			file.RepoRelativePath, err)
+		return result
+	}
 
-		// Skip if already a symlink
-		if file.IsSymlink {
-			result.Skipped = true
-			result.SkipReason = "already symlink"
-			results = append(results, result)
-			continue
+	// Read file content before any modifications
+	content, err := os.ReadFile(file.AbsolutePath)
+	if err != nil {
+		result.Skipped = true
+		result.SkipReason = "read failed"
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: failed to read file: %v",
+			file.RepoRelativePath, err)
+		return result
+	}
+
+	// Mode, mtime and xattrs travel with the content into storage
+	meta, err := storage.ReadMeta(file.AbsolutePath)
+	if err != nil {
+		result.Skipped = true
+		result.SkipReason = "stat failed"
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: failed to read file metadata: %v",
+			file.RepoRelativePath, err)
+		return result
+	}
+
+	// stored is what storage holds once the file is linked, rollback undoes
+	// the storage change if linking fails
+	var stored []byte
+	var rollback func()
+
+	// Durably create the storage file, failing if it already exists
+	err = storage.CreateAtomic(storagePath, content, meta.Mode)
+	switch {
+	case err == nil:
+		stored = content
+		rollback = func() { _ = os.Remove(storagePath) }
+
+	case os.IsExist(err):
+		stored, rollback = resolveSaveConflict(file, content, meta, storagePath, opts, &result)
+		if result.Skipped {
+			return result
 		}
 
-		// Get storage path
-		storagePath, err := opts.PathConverter.GetStoragePath(file.RepoRelativePath)
-		if err != nil {
-			result.Skipped = true
-			result.SkipReason = "path conversion error"
-			result.Error = err
-			result.Warning = fmt.Sprintf("Skipping %s: %v", file.RepoRelativePath, err)
-			results = append(results, result)
-			continue
-		}
-		result.StoragePath = storagePath
-
-		// Ensure storage directory exists
-		if err := opts.PathConverter.EnsureStorageDir(); err != nil {
-			result.Skipped = true
-			result.SkipReason = "storage directory creation failed"
-			result.Error = err
-			result.Warning = fmt.Sprintf("Skipping %s: failed to create storage directory: %v",
+	default:
+		result.Skipped = true
+		result.SkipReason = "storage file creation failed"
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: failed to create storage file: %v",
+			file.RepoRelativePath, err)
+		return result
+	}
+
+	if bytes.Equal(stored, content) {
+		if err := storage.ApplyMeta(storagePath, meta); err != nil {
+			result.Warning = fmt.Sprintf("%s: saved, but failed to preserve file metadata: %v",
 				file.RepoRelativePath, err)
-			results = append(results, result)
-			continue
 		}
+	}
 
-		// Read file content before any modifications
-		content, err := os.ReadFile(file.AbsolutePath)
-		if err != nil {
-			result.Skipped = true
-			result.SkipReason = "read failed"
-			result.Error = err
-			result.Warning = fmt.Sprintf("Skipping %s: failed to read file: %v",
-				file.RepoRelativePath, err)
-			results = append(results, result)
-			continue
+	// Never remove the original before storage provably holds what it should
+	if err := storage.VerifyContent(storagePath, stored); err != nil {
+		rollback()
+		result.Skipped = true
+		result.SkipReason = "verify failed"
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: storage does not hold the saved content: %v",
+			file.RepoRelativePath, err)
+		return result
+	}
+
+	// Remove original file
+	if err := os.Remove(file.AbsolutePath); err != nil {
+		result.Skipped = true
+		result.SkipReason = "remove failed"
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: failed to remove original file: %v",
+			file.RepoRelativePath, err)
+		// Clean up storage file
+		rollback()
+		return result
+	}
+
+	// Get absolute storage path for symlink
+	absStoragePath, err := filepath.Abs(storagePath)
+	if err != nil {
+		restoreOriginal(file, content, meta, storagePath, "absolute path failed", "failed to get absolute path",
+			err, rollback, &result)
+		return result
+	}
+
+	// Create symlink, hardlink or copy
+	if err := placeFile(absStoragePath, file.AbsolutePath, mode, linkStyleFor(opts.Manifest, opts.LinkStyle)); err != nil {
+		restoreOriginal(file, content, meta, storagePath, "symlink creation failed", "failed to create symlink",
+			err, rollback, &result)
+		return result
+	}
+
+	if opts.Manifest != nil {
+		// keep-both leaves storage as it was, so there is no new version to record
+		_, known := opts.Manifest.Files[storageName]
+		if result.Resolution != SaveConflictKeepBoth || !known {
+			opts.Manifest.Record(storageName, stored)
+			if _, err := opts.PathConverter.RecordVersion(storageName, stored); err != nil {
+				result.Warning = fmt.Sprintf("%s: saved, but failed to record version history: %v",
+					file.RepoRelativePath, err)
+			}
+		}
+		if opts.LinkMode != "" {
+			opts.Manifest.SetLinkMode(storageName, opts.LinkMode)
+		}
+		if absPath, err := filepath.Abs(file.AbsolutePath); err == nil {
+			opts.Manifest.SetEjected(storageName, absPath, false)
+			if mode != storage.LinkSymlink {
+				opts.Manifest.SetCopyHash(storageName, absPath, storage.HashContent(stored))
+			}
 		}
+	}
+
+	result.Success = true
+	return result
+}
 
-		// Atomically create storage file with O_EXCL to prevent race conditions
-		storageFile, err := os.OpenFile(storagePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+// replaceCopy places a managed hardlink or copy again in opts.LinkMode. The new
+// link or copy is renamed over the old one, so the path is never empty. A copy
+// that differs from storage is left for sync.
+func replaceCopy(file files.ClaudeFile, storagePath string, opts SaveOptions, result *SaveResult) {
+	storageName := filepath.Base(storagePath)
+	skip := func(reason string, err error) {
+		result.Skipped = true
+		result.SkipReason = reason
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: %s", file.RepoRelativePath, reason)
 		if err != nil {
-			if os.IsExist(err) {
-				// File already exists - skip with warning
-				result.Skipped = true
-				result.SkipReason = "storage file exists"
-				result.Warning = fmt.Sprintf("Skipping %s: storage file already exists at %s",
-					file.RepoRelativePath, storagePath)
-			} else {
-				// Other error
-				result.Skipped = true
-				result.SkipReason = "storage file creation failed"
-				result.Error = err
-				result.Warning = fmt.Sprintf("Skipping %s: failed to create storage file: %v",
-					file.RepoRelativePath, err)
+			result.Warning += fmt.Sprintf(": %v", err)
+		}
+	}
+
+	placed, err := isPlacedCopy(storagePath, file.AbsolutePath, opts.Manifest.LinkModeFor(storageName))
+	if err != nil || !placed {
+		skip("copy differs from storage, run sync first", err)
+		return
+	}
+	absStoragePath, err := filepath.Abs(storagePath)
+	if err != nil {
+		skip("failed to get absolute path", err)
+		return
+	}
+	absPath, err := filepath.Abs(file.AbsolutePath)
+	if err != nil {
+		skip("failed to get absolute path", err)
+		return
+	}
+
+	tmp := file.AbsolutePath + ".claude-md.tmp"
+	_ = os.Remove(tmp)
+	if err := placeFile(absStoragePath, tmp, opts.LinkMode, linkStyleFor(opts.Manifest, opts.LinkStyle)); err != nil {
+		_ = os.Remove(tmp)
+		skip("failed to place file", err)
+		return
+	}
+	if err := os.Rename(tmp, file.AbsolutePath); err != nil {
+		_ = os.Remove(tmp)
+		skip("failed to replace file", err)
+		return
+	}
+
+	opts.Manifest.SetLinkMode(storageName, opts.LinkMode)
+	opts.Manifest.RemoveCopy(storageName, absPath)
+	if opts.LinkMode != storage.LinkSymlink {
+		if content, err := os.ReadFile(storagePath); err == nil {
+			opts.Manifest.SetCopyHash(storageName, absPath, storage.HashContent(content))
+		}
+	}
+	result.Success = true
+}
+
+// resolveSaveConflict applies the conflict policy when storage already has the file.
+// It returns the content storage holds afterwards and a func that undoes the change,
+// or marks the result as skipped.
+func resolveSaveConflict(file files.ClaudeFile, content []byte, meta storage.FileMeta, storagePath string,
+	opts SaveOptions, result *SaveResult) ([]byte, func()) {
+
+	skip := func(reason string, err error) ([]byte, func()) {
+		result.Skipped = true
+		result.SkipReason = reason
+		result.Error = err
+		result.Warning = fmt.Sprintf("Skipping %s: %s: %v", file.RepoRelativePath, reason, err)
+		return nil, nil
+	}
+
+	existing, err := os.ReadFile(storagePath)
+	if err != nil {
+		return skip("failed to read storage file", err)
+	}
+	storageName := filepath.Base(storagePath)
+	existingMeta, err := storage.ReadMeta(storagePath)
+	if err != nil {
+		return skip("stat failed", err)
+	}
+	restoreExisting := func() {
+		if storage.WriteAtomic(storagePath, existing, existingMeta.Mode) == nil {
+			_ = storage.ApplyMeta(storagePath, existingMeta)
+		}
+	}
+
+	// A regular file newer than its stored copy is usually a symlink an editor
+	// replaced by writing a temp file and renaming it over the link
+	stale, err := IsStale(file.AbsolutePath, storagePath)
+	if err != nil {
+		return skip("stat failed", err)
+	}
+
+	if opts.Update && (stale || bytes.Equal(existing, content)) {
+		if !bytes.Equal(existing, content) {
+			if _, err := opts.PathConverter.RecordVersion(storageName, existing); err != nil {
+				return skip("failed to record previous version", err)
+			}
+			if err := storage.WriteAtomic(storagePath, content, meta.Mode); err != nil {
+				restoreExisting()
+				return skip("write failed", err)
 			}
-			results = append(results, result)
-			continue
-		}
-
-		// Write content to storage file
-		if _, err := storageFile.Write(content); err != nil {
-			_ = storageFile.Close()
-			_ = os.Remove(storagePath) // Clean up partial file
-			result.Skipped = true
-			result.SkipReason = "write failed"
-			result.Error = err
-			result.Warning = fmt.Sprintf("Skipping %s: failed to write to storage: %v",
-				file.RepoRelativePath, err)
-			results = append(results, result)
-			continue
 		}
-		_ = storageFile.Close()
+		result.Updated = true
+		return content, restoreExisting
+	}
 
-		// Remove original file
-		if err := os.Remove(file.AbsolutePath); err != nil {
-			result.Skipped = true
-			result.SkipReason = "remove failed"
-			result.Error = err
-			result.Warning = fmt.Sprintf("Skipping %s: failed to remove original file: %v",
-				file.RepoRelativePath, err)
-			// Clean up storage file
-			_ = os.Remove(storagePath)
-			results = append(results, result)
-			continue
+	policy := opts.OnConflict
+	if policy == SaveConflictPrompt {
+		policy = SaveConflictSkip
+		if opts.Prompt != nil {
+			p, err := opts.Prompt(file, storagePath)
+			if err != nil {
+				return skip("prompt failed", err)
+			}
+			policy = p
 		}
+	}
+
+	if policy == "" || policy == SaveConflictSkip {
+		result.Skipped = true
+		result.SkipReason = "storage file exists"
+		result.Warning = fmt.Sprintf("Skipping %s: storage file already exists at %s",
+			file.RepoRelativePath, storagePath)
+		if stale {
+			result.Warning += " (the repo file is newer, use --update to re-absorb it)"
+		}
+		return nil, nil
+	}
 
-		// Get absolute storage path for symlink
-		absStoragePath, err := filepath.Abs(storagePath)
+	result.Resolution = policy
+
+	switch policy {
+	case SaveConflictOverwrite:
+		backup, err := opts.PathConverter.WriteBackup(storageName, existing)
 		if err != nil {
-			result.Skipped = true
-			result.SkipReason = "absolute path failed"
-			result.Error = err
-			// Try to restore original file
-			if restoreErr := os.WriteFile(file.AbsolutePath, content, 0644); restoreErr != nil {
-				// CRITICAL: Failed to restore file
-				result.Error = fmt.Errorf("CRITICAL: failed to restore file after error (data is in storage): %w (original error: %v)",
-					restoreErr, err)
-				result.Warning = fmt.Sprintf("CRITICAL: %s - original file deleted but restore failed. Content saved in %s",
-					file.RepoRelativePath, storagePath)
-			} else {
-				// Successfully restored, clean up storage
-				_ = os.Remove(storagePath)
-				result.Warning = fmt.Sprintf("Skipping %s: failed to get absolute path: %v",
-					file.RepoRelativePath, err)
-			}
-			results = append(results, result)
-			continue
-		}
-
-		// Create symlink
-		if err := os.Symlink(absStoragePath, file.AbsolutePath); err != nil {
-			result.Skipped = true
-			result.SkipReason = "symlink creation failed"
-			result.Error = err
-			// Try to restore original file
-			if restoreErr := os.WriteFile(file.AbsolutePath, content, 0644); restoreErr != nil {
-				// CRITICAL: Failed to restore file
-				result.Error = fmt.Errorf("CRITICAL: failed to restore file after error (data is in storage): %w (original error: %v)",
-					restoreErr, err)
-				result.Warning = fmt.Sprintf("CRITICAL: %s - original file deleted but restore failed. Content saved in %s",
-					file.RepoRelativePath, storagePath)
-			} else {
-				// Successfully restored, clean up storage
-				_ = os.Remove(storagePath)
-				result.Warning = fmt.Sprintf("Skipping %s: failed to create symlink: %v",
-					file.RepoRelativePath, err)
+			return skip("backup failed", err)
+		}
+		result.BackupPath = backup
+		if err := storage.WriteAtomic(storagePath, content, meta.Mode); err != nil {
+			restoreExisting()
+			return skip("write failed", err)
+		}
+		return content, restoreExisting
+
+	case SaveConflictKeepBoth:
+		copyPath, err := opts.PathConverter.BackupPath(storageName)
+		if err != nil {
+			return skip("write failed", err)
+		}
+		if err := storage.CreateAtomic(copyPath, content, meta.Mode); err != nil {
+			return skip("write failed", err)
+		}
+		_ = storage.ApplyMeta(copyPath, meta)
+		if err := storage.VerifyContent(copyPath, content); err != nil {
+			_ = os.Remove(copyPath)
+			return skip("verify failed", err)
+		}
+		result.BackupPath = copyPath
+		return existing, func() { _ = os.Remove(copyPath) }
+
+	case SaveConflictMerge:
+		// The last version we saved is the common ancestor of storage and the repo file.
+		// Without one, every difference between the two shows up as a conflict.
+		var base []byte
+		if opts.Manifest != nil {
+			if entry, ok := opts.Manifest.Files[storageName]; ok {
+				base, _ = opts.PathConverter.LoadVersion(storageName, entry.Hash)
 			}
-			results = append(results, result)
-			continue
 		}
+		merged := merge.ThreeWay(base, existing, content, merge.Labels{
+			Ours:   "storage",
+			Theirs: file.RepoRelativePath,
+		})
+		result.Conflicts = merged.Conflicts
+		if err := storage.WriteAtomic(storagePath, merged.Content, meta.Mode); err != nil {
+			restoreExisting()
+			return skip("write failed", err)
+		}
+		if merged.Conflicts > 0 {
+			result.Warning = fmt.Sprintf("%s: merged with %d conflict(s), resolve the markers in %s",
+				file.RepoRelativePath, merged.Conflicts, storagePath)
+		}
+		return merged.Content, restoreExisting
+	}
+
+	return skip("invalid conflict policy", fmt.Errorf("unknown policy %q", policy))
+}
+
+// restoreOriginal puts the original file back after linking failed and undoes the storage change
+func restoreOriginal(file files.ClaudeFile, content []byte, meta storage.FileMeta, storagePath, reason, what string,
+	err error, rollback func(), result *SaveResult) {
 
-		result.Success = true
-		results = append(results, result)
+	result.Skipped = true
+	result.SkipReason = reason
+	result.Error = err
+
+	// Try to restore original file
+	if restoreErr := os.WriteFile(file.AbsolutePath, content, meta.Mode); restoreErr != nil {
+		// CRITICAL: Failed to restore file
+		result.Error = fmt.Errorf("CRITICAL: failed to restore file after error (data is in storage): %w (original error: %v)",
+			restoreErr, err)
+		result.Warning = fmt.Sprintf("CRITICAL: %s - original file deleted but restore failed. Content saved in %s",
+			file.RepoRelativePath, storagePath)
+		if result.BackupPath != "" {
+			result.Warning += fmt.Sprintf(" (see also %s)", result.BackupPath)
+		}
+		return
 	}
 
-	return results
+	_ = storage.ApplyMeta(file.AbsolutePath, meta)
+
+	// Successfully restored, clean up storage
+	rollback()
+	result.Warning = fmt.Sprintf("Skipping %s: %s: %v", file.RepoRelativePath, what, err)
+}
+
+// IsStale reports whether the regular file at path was modified after its stored copy
+func IsStale(path, storagePath string) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() {
+		return false, nil
+	}
+
+	storedInfo, err := os.Stat(storagePath)
+	if err != nil {
+		return false, err
+	}
+	return info.ModTime().After(storedInfo.ModTime()), nil
 }
@@ -0,0 +1,126 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// FileState describes how a CLAUDE.md file in the working tree relates to storage
+type FileState string
+
+const (
+	StateLinked      FileState = "linked"       // Linked, or an up to date hardlink or copy
+	StateMissing     FileState = "missing"      // Stored but not in the working tree
+	StateMoved       FileState = "moved"        // The link was moved to another path
+	StateModified    FileState = "modified"     // A hardlink or copy that differs from storage
+	StateConflict    FileState = "conflict"     // A regular file where the link should be
+	StateWrongTarget FileState = "wrong target" // A symlink that points somewhere else
+	StateUnsaved     FileState = "unsaved"      // A regular file that is not in storage
+	StateTracked     FileState = "tracked"      // A file git tracks, left alone
+	StateEjected     FileState = "ejected"      // Ejected from management, left alone
+)
+
+// FileStatus is the state of one CLAUDE.md file
+type FileStatus struct {
+	RepoRelativePath string
+	State            FileState
+	MovedTo          string // Repo relative path of the moved link, for StateMoved
+}
+
+// StatusOptions contains options for status operation
+type StatusOptions struct {
+	RepoRoot string
+	Manifest *storage.Manifest
+}
+
+// Status reports the state of every stored file and every unsaved CLAUDE.md in
+// the working tree. A stored file whose link turns up at another path, as when
+// its directory was moved, is reported as moved.
+func Status(claudeFiles []files.ClaudeFile, storedFiles []files.StoredFile, opts StatusOptions) []FileStatus {
+	var statuses []FileStatus
+
+	storedPaths := make(map[string]bool)
+	for _, stored := range storedFiles {
+		storedPaths[stored.RepoRelativePath] = true
+	}
+	accounted := make(map[string]bool)
+
+	for _, stored := range storedFiles {
+		status := FileStatus{RepoRelativePath: stored.RepoRelativePath}
+		targetPath := filepath.Join(opts.RepoRoot, stored.RepoRelativePath)
+		mode := linkModeFor(opts.Manifest, stored.StorageFilename, "")
+
+		info, err := os.Lstat(targetPath)
+		switch {
+		case ejected(opts.Manifest, stored.StorageFilename, targetPath):
+			status.State = StateEjected
+		case err != nil:
+			status.State = StateMissing
+			for _, file := range claudeFiles {
+				if storedPaths[file.RepoRelativePath] || accounted[file.RepoRelativePath] {
+					continue
+				}
+				if movedLink(file, targetPath, stored.StoragePath, mode) {
+					status.State = StateMoved
+					status.MovedTo = file.RepoRelativePath
+					accounted[file.RepoRelativePath] = true
+					break
+				}
+			}
+		case info.Mode()&os.ModeSymlink != 0:
+			status.State = StateWrongTarget
+			if correct, _ := pointsTo(targetPath, stored.StoragePath); correct {
+				status.State = StateLinked
+			}
+		case mode != storage.LinkSymlink:
+			status.State = StateModified
+			if placed, _ := isPlacedCopy(stored.StoragePath, targetPath, mode); placed {
+				status.State = StateLinked
+			}
+		default:
+			status.State = StateConflict
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, file := range claudeFiles {
+		if storedPaths[file.RepoRelativePath] || accounted[file.RepoRelativePath] || file.IsSymlink {
+			continue
+		}
+		state := StateUnsaved
+		if file.Tracked {
+			state = StateTracked
+		}
+		statuses = append(statuses, FileStatus{RepoRelativePath: file.RepoRelativePath, State: state})
+	}
+
+	return statuses
+}
+
+// ejected reports whether the working tree file at targetPath was ejected
+func ejected(manifest *storage.Manifest, storageName, targetPath string) bool {
+	if manifest == nil {
+		return false
+	}
+	absPath, err := filepath.Abs(targetPath)
+	return err == nil && manifest.IsEjected(storageName, absPath)
+}
+
+// movedLink reports whether file is the link or hardlink that used to live at oldTarget
+func movedLink(file files.ClaudeFile, oldTarget, storagePath string, mode storage.LinkMode) bool {
+	if file.IsSymlink {
+		if correct, _ := pointsTo(file.AbsolutePath, storagePath); correct {
+			return true
+		}
+		return wasLinkedFrom(file.AbsolutePath, oldTarget, storagePath)
+	}
+	if mode == storage.LinkHardlink {
+		placed, _ := isPlacedCopy(storagePath, file.AbsolutePath, mode)
+		return placed
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+)
+
+// SyncAction describes what sync did with a hardlink or copy
+type SyncAction string
+
+const (
+	SyncInSync   SyncAction = "in sync"  // Both sides already match
+	SyncPushed   SyncAction = "pushed"   // Working tree edits were copied into storage
+	SyncPulled   SyncAction = "pulled"   // Storage edits were copied into the working tree
+	SyncConflict SyncAction = "conflict" // Both sides changed since the last sync
+)
+
+// SyncResult represents the result of reconciling a hardlink or copy with storage
+type SyncResult struct {
+	RepoRelativePath string
+	StoragePath      string
+	Action           SyncAction
+	Warning          string
+	Error            error
+}
+
+// SyncOptions contains options for sync operation
+type SyncOptions struct {
+	RepoRoot      string
+	PathConverter *storage.PathConverter
+	Manifest      *storage.Manifest // Required, holds the hashes both sides had at the last sync
+}
+
+// SyncCopies reconciles hardlinked and copied files with storage using the hashes
+// recorded at the last sync: the side that changed is copied to the other, and
+// files changed on both sides are reported as conflicts and left alone.
+// Files managed as symlinks, or not present in the working tree, are ignored.
+func SyncCopies(storedFiles []files.StoredFile, opts SyncOptions) []SyncResult {
+	var results []SyncResult
+
+	for _, stored := range storedFiles {
+		mode := opts.Manifest.LinkModeFor(stored.StorageFilename)
+		if mode == storage.LinkSymlink {
+			continue
+		}
+
+		targetPath := filepath.Join(opts.RepoRoot, stored.RepoRelativePath)
+		info, err := os.Lstat(targetPath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		result := SyncResult{
+			RepoRelativePath: stored.RepoRelativePath,
+			StoragePath:      stored.StoragePath,
+		}
+		syncCopy(stored, targetPath, mode, opts, &result)
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func syncCopy(stored files.StoredFile, targetPath string, mode storage.LinkMode, opts SyncOptions,
+	result *SyncResult) {
+
+	fail := func(what string, err error) {
+		result.Error = fmt.Errorf("%s: %w", what, err)
+		result.Warning = fmt.Sprintf("Skipping %s: %s: %v", stored.RepoRelativePath, what, err)
+	}
+
+	absTargetPath, err := filepath.Abs(targetPath)
+	if err != nil {
+		fail("failed to get absolute path", err)
+		return
+	}
+	repoContent, err := os.ReadFile(targetPath)
+	if err != nil {
+		fail("failed to read file", err)
+		return
+	}
+	storedContent, err := os.ReadFile(stored.StoragePath)
+	if err != nil {
+		fail("failed to read stored file", err)
+		return
+	}
+
+	repoHash := storage.HashContent(repoContent)
+	storedHash := storage.HashContent(storedContent)
+	baseHash := opts.Manifest.CopyHash(stored.StorageFilename, absTargetPath)
+
+	switch {
+	case repoHash == storedHash:
+		result.Action = SyncInSync
+		// Both sides were edited alike, which makes their content the new base
+		if entry, ok := opts.Manifest.Files[stored.StorageFilename]; !ok || entry.Hash != storedHash {
+			if _, err := opts.PathConverter.RecordVersion(stored.StorageFilename, storedContent); err != nil {
+				fail("failed to record version", err)
+				return
+			}
+			opts.Manifest.Record(stored.StorageFilename, storedContent)
+		}
+		// A hardlink that an editor replaced by renaming still holds the right content
+		if mode == storage.LinkHardlink {
+			if placed, _ := isPlacedCopy(stored.StoragePath, targetPath, mode); !placed {
+				if err := replaceWithStored(stored.StoragePath, targetPath, mode); err != nil {
+					fail("failed to relink", err)
+					return
+				}
+			}
+		}
+
+	case baseHash == storedHash:
+		// Only the working tree changed
+		if _, err := opts.PathConverter.RecordVersion(stored.StorageFilename, storedContent); err != nil {
+			fail("failed to record previous version", err)
+			return
+		}
+		meta, err := storage.ReadMeta(targetPath)
+		if err != nil {
+			fail("failed to read file metadata", err)
+			return
+		}
+		if err := storage.WriteAtomic(stored.StoragePath, repoContent, meta.Mode); err != nil {
+			fail("failed to write to storage", err)
+			return
+		}
+		_ = storage.ApplyMeta(stored.StoragePath, meta)
+		opts.Manifest.Record(stored.StorageFilename, repoContent)
+		if mode == storage.LinkHardlink {
+			if err := replaceWithStored(stored.StoragePath, targetPath, mode); err != nil {
+				fail("failed to relink", err)
+				return
+			}
+		}
+		result.Action = SyncPushed
+
+	case baseHash == repoHash:
+		// Only storage changed
+		if err := replaceWithStored(stored.StoragePath, targetPath, mode); err != nil {
+			fail("failed to update working tree copy", err)
+			return
+		}
+		result.Action = SyncPulled
+
+	default:
+		result.Action = SyncConflict
+		result.Warning = fmt.Sprintf("%s changed in both the working tree and storage (%s), "+
+			"make them match and run sync again", stored.RepoRelativePath, stored.StoragePath)
+		return
+	}
+
+	// Both sides now hold the same content
+	if result.Action == SyncPulled {
+		repoHash = storedHash
+	}
+	opts.Manifest.SetCopyHash(stored.StorageFilename, absTargetPath, repoHash)
+}
+
+// replaceWithStored atomically replaces targetPath with a fresh hardlink or copy of storagePath
+func replaceWithStored(storagePath, targetPath string, mode storage.LinkMode) error {
+	absStoragePath, err := filepath.Abs(storagePath)
+	if err != nil {
+		return err
+	}
+
+	tmp := targetPath + ".claude-md.tmp"
+	_ = os.Remove(tmp)
+	if err := placeFile(absStoragePath, tmp, mode, ""); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, targetPath); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package operations_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/files"
+	"github.com/kapetan-io/claude-md.go/internal/operations"
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncCopiesHardlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoDir := filepath.Join(tmpDir, "repo")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+
+	pc := &storage.PathConverter{
+		StorageRoot: filepath.Join(tmpDir, "storage"),
+		RepoName:    "test.git",
+	}
+	manifest, err := pc.LoadManifest()
+	require.NoError(t, err)
+	manifest.LinkMode = storage.LinkHardlink
+
+	repoFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(repoFile, []byte("original"), 0644))
+
+	claudeFiles, err := files.FindClaudeFiles(repoDir)
+	require.NoError(t, err)
+	saveResults := operations.SaveFiles(claudeFiles, operations.SaveOptions{
+		RepoRoot:      repoDir,
+		PathConverter: pc,
+		Manifest:      manifest,
+	})
+	require.Len(t, saveResults, 1)
+	require.True(t, saveResults[0].Success, saveResults[0].Warning)
+
+	storagePath, err := pc.GetStoragePath("CLAUDE.md")
+	require.NoError(t, err)
+	sameFile := func() bool {
+		a, err := os.Stat(repoFile)
+		require.NoError(t, err)
+		b, err := os.Stat(storagePath)
+		require.NoError(t, err)
+		return os.SameFile(a, b)
+	}
+	assert.True(t, sameFile())
+
+	// An editor replaces the hardlink with a new file
+	tmp := repoFile + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte("edited"), 0644))
+	require.NoError(t, os.Rename(tmp, repoFile))
+	assert.False(t, sameFile())
+
+	storedFiles, err := files.FindStoredFiles(pc.GetRepoStorageDir(), pc)
+	require.NoError(t, err)
+	results := operations.SyncCopies(storedFiles, operations.SyncOptions{
+		RepoRoot:      repoDir,
+		PathConverter: pc,
+		Manifest:      manifest,
+	})
+
+	require.Len(t, results, 1)
+	assert.Equal(t, operations.SyncPushed, results[0].Action)
+	content, err := os.ReadFile(storagePath)
+	require.NoError(t, err)
+	assert.Equal(t, "edited", string(content))
+	assert.True(t, sameFile(), "sync should restore the hardlink")
+
+	// Nothing left to do
+	results = operations.SyncCopies(storedFiles, operations.SyncOptions{
+		RepoRoot:      repoDir,
+		PathConverter: pc,
+		Manifest:      manifest,
+	})
+	require.Len(t, results, 1)
+	assert.Equal(t, operations.SyncInSync, results[0].Action)
+}
+
+func TestSyncCopiesIdenticalEdits(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoDir := filepath.Join(tmpDir, "repo")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+
+	pc := &storage.PathConverter{
+		StorageRoot: filepath.Join(tmpDir, "storage"),
+		RepoName:    "test.git",
+	}
+	manifest, err := pc.LoadManifest()
+	require.NoError(t, err)
+	manifest.LinkMode = storage.LinkCopy
+
+	repoFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(repoFile, []byte("original"), 0644))
+
+	claudeFiles, err := files.FindClaudeFiles(repoDir)
+	require.NoError(t, err)
+	saveResults := operations.SaveFiles(claudeFiles, operations.SaveOptions{
+		RepoRoot:      repoDir,
+		PathConverter: pc,
+		Manifest:      manifest,
+	})
+	require.Len(t, saveResults, 1)
+	require.True(t, saveResults[0].Success, saveResults[0].Warning)
+
+	storagePath, err := pc.GetStoragePath("CLAUDE.md")
+	require.NoError(t, err)
+	storedFiles, err := files.FindStoredFiles(pc.GetRepoStorageDir(), pc)
+	require.NoError(t, err)
+	sync := func() operations.SyncResult {
+		results := operations.SyncCopies(storedFiles, operations.SyncOptions{
+			RepoRoot:      repoDir,
+			PathConverter: pc,
+			Manifest:      manifest,
+		})
+		require.Len(t, results, 1)
+		require.NoError(t, results[0].Error)
+		return results[0]
+	}
+
+	// Both sides get the same edit
+	require.NoError(t, os.WriteFile(repoFile, []byte("same edit"), 0644))
+	require.NoError(t, os.WriteFile(storagePath, []byte("same edit"), 0644))
+	assert.Equal(t, operations.SyncInSync, sync().Action)
+	assert.Equal(t, storage.HashContent([]byte("same edit")), manifest.Files["CLAUDE.md"].Hash)
+	base, err := pc.LoadVersion("CLAUDE.md", manifest.Files["CLAUDE.md"].Hash)
+	require.NoError(t, err)
+	assert.Equal(t, "same edit", string(base))
+
+	// A later edit on one side is pushed, not flagged as a conflict
+	require.NoError(t, os.WriteFile(repoFile, []byte("repo edit"), 0644))
+	assert.Equal(t, operations.SyncPushed, sync().Action)
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteAtomic durably replaces path with content. The content goes to a temp
+// file in the same directory, which is fsynced and renamed over path before
+// the directory itself is fsynced, so a crash leaves either the old file or
+// the complete new one.
+func WriteAtomic(path string, content []byte, perm os.FileMode) error {
+	tmp, err := writeTemp(path, content, perm)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// CreateAtomic is WriteAtomic for a file that must not exist yet. If path
+// exists it returns an error for which os.IsExist is true.
+func CreateAtomic(path string, content []byte, perm os.FileMode) error {
+	tmp, err := writeTemp(path, content, perm)
+	if err != nil {
+		return err
+	}
+	// Unlike rename, link refuses to replace an existing file
+	err = os.Link(tmp, path)
+	_ = os.Remove(tmp)
+	if err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// VerifyContent checks that path holds exactly content, by size and then by hash
+func VerifyContent(path string, content []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() != int64(len(content)) {
+		return fmt.Errorf("%s has %d bytes, expected %d", path, info.Size(), len(content))
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if sha256.Sum256(written) != sha256.Sum256(content) {
+		return fmt.Errorf("%s does not match the content written", path)
+	}
+	return nil
+}
+
+// writeTemp writes content to a hidden, fsynced temp file next to path and returns its name
+func writeTemp(path string, content []byte, perm os.FileMode) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	tmp := f.Name()
+
+	fail := func(err error) (string, error) {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if _, err := f.Write(content); err != nil {
+		return fail(err)
+	}
+	if err := f.Chmod(perm); err != nil {
+		return fail(err)
+	}
+	if err := f.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// syncDir fsyncs a directory so a rename or link in it survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CLAUDE.md")
+
+	require.NoError(t, storage.WriteAtomic(path, []byte("first"), 0600))
+	require.NoError(t, storage.WriteAtomic(path, []byte("second"), 0644))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	// No temp files are left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestCreateAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CLAUDE.md")
+
+	require.NoError(t, storage.CreateAtomic(path, []byte("first"), 0644))
+
+	err := storage.CreateAtomic(path, []byte("second"), 0644)
+	require.Error(t, err)
+	assert.True(t, os.IsExist(err))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestVerifyContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CLAUDE.md")
+	require.NoError(t, os.WriteFile(path, []byte("content"), 0644))
+
+	assert.NoError(t, storage.VerifyContent(path, []byte("content")))
+	assert.ErrorContains(t, storage.VerifyContent(path, []byte("content and more")), "has 7 bytes, expected 16")
+	assert.ErrorContains(t, storage.VerifyContent(path, []byte("CONTENT")), "does not match")
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	historyDirName = ".history"
+	backupDirName  = ".backups"
+	trashDirName   = ".trash"
+)
+
+// RecordVersion keeps a copy of content in the history for storageName
+// Versions are content addressed, so recording the same content twice is a no-op
+// Returns the hash the version was recorded under
+func (pc *PathConverter) RecordVersion(storageName string, content []byte) (string, error) {
+	hash := HashContent(content)
+	dir := filepath.Join(pc.GetRepoStorageDir(), historyDirName, storageName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	path := filepath.Join(dir, hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+
+	if err := WriteAtomic(path, content, 0600); err != nil {
+		return "", fmt.Errorf("failed to record version: %w", err)
+	}
+	return hash, nil
+}
+
+// LoadVersion returns a previously recorded version of storageName
+func (pc *PathConverter) LoadVersion(storageName, hash string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(pc.GetRepoStorageDir(), historyDirName, storageName, hash))
+}
+
+// WriteBackup saves content as a timestamped backup of storageName
+// Returns the path of the backup file
+func (pc *PathConverter) WriteBackup(storageName string, content []byte) (string, error) {
+	path, err := pc.BackupPath(storageName)
+	if err != nil {
+		return "", err
+	}
+	if err := CreateAtomic(path, content, 0600); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+	return path, nil
+}
+
+// BackupPath returns a new timestamped path for a copy of storageName under
+// .backups, where discovery of stored files never picks it up
+func (pc *PathConverter) BackupPath(storageName string) (string, error) {
+	dir := filepath.Join(pc.GetRepoStorageDir(), backupDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	return filepath.Join(dir, storageName+"."+Timestamp()), nil
+}
+
+// Timestamp returns the current time in the format used to suffix backups and copies
+func Timestamp() string {
+	return time.Now().UTC().Format("20060102-150405.000000000")
+}
+
+// RemoveHistory deletes every recorded version of storageName
+func (pc *PathConverter) RemoveHistory(storageName string) error {
+	return os.RemoveAll(filepath.Join(pc.GetRepoStorageDir(), historyDirName, storageName))
+}
+
+// RenameHistory moves the recorded versions of oldName to newName
+func (pc *PathConverter) RenameHistory(oldName, newName string) error {
+	dir := filepath.Join(pc.GetRepoStorageDir(), historyDirName)
+	if _, err := os.Stat(filepath.Join(dir, oldName)); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.Rename(filepath.Join(dir, oldName), filepath.Join(dir, newName)); err != nil {
+		return fmt.Errorf("failed to move history: %w", err)
+	}
+	return nil
+}
+
+// MoveToTrash moves storageName and its history into a timestamped directory
+// under .trash, where it can be recovered by moving it back
+// Returns the path the stored file was moved to
+func (pc *PathConverter) MoveToTrash(storageName string) (string, error) {
+	dir := filepath.Join(pc.GetRepoStorageDir(), trashDirName, Timestamp())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create trash directory: %w", err)
+	}
+
+	path := filepath.Join(dir, storageName)
+	if err := os.Rename(filepath.Join(pc.GetRepoStorageDir(), storageName), path); err != nil {
+		return "", fmt.Errorf("failed to move to trash: %w", err)
+	}
+
+	history := filepath.Join(pc.GetRepoStorageDir(), historyDirName, storageName)
+	if _, err := os.Stat(history); err == nil {
+		if err := os.MkdirAll(filepath.Join(dir, historyDirName), 0700); err != nil {
+			return path, fmt.Errorf("failed to move history to trash: %w", err)
+		}
+		if err := os.Rename(history, filepath.Join(dir, historyDirName, storageName)); err != nil {
+			return path, fmt.Errorf("failed to move history to trash: %w", err)
+		}
+	}
+	return path, nil
+}
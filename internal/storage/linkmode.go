@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// LinkMode is how a stored file is placed in the working tree
+type LinkMode string
+
+const (
+	LinkSymlink  LinkMode = "symlink"  // Symlink to the stored file (default)
+	LinkHardlink LinkMode = "hardlink" // Hard link to the stored file, requires the same filesystem
+	LinkCopy     LinkMode = "copy"     // Independent copy kept in step by sync
+)
+
+// LinkModes lists the supported link modes
+var LinkModes = []LinkMode{LinkSymlink, LinkHardlink, LinkCopy}
+
+// ParseLinkMode validates a link mode name
+func ParseLinkMode(s string) (LinkMode, error) {
+	for _, m := range LinkModes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("invalid link mode %q (must be one of: symlink, hardlink, copy)", s)
+}
+
+// LinkModeFor returns the link mode for storageName: its own setting, else the repo default, else symlink
+func (m *Manifest) LinkModeFor(storageName string) LinkMode {
+	if entry, ok := m.Files[storageName]; ok && entry.LinkMode != "" {
+		return entry.LinkMode
+	}
+	if m.LinkMode != "" {
+		return m.LinkMode
+	}
+	return LinkSymlink
+}
+
+// SetLinkMode records a per-file link mode for storageName
+func (m *Manifest) SetLinkMode(storageName string, mode LinkMode) {
+	entry, ok := m.Files[storageName]
+	if !ok {
+		entry = &FileEntry{}
+		m.Files[storageName] = entry
+	}
+	entry.LinkMode = mode
+}
+
+// LinkStyle is how a symlink refers to its stored file
+type LinkStyle string
+
+const (
+	LinkAbsolute LinkStyle = "absolute" // Absolute path into storage (default)
+	LinkRelative LinkStyle = "relative" // Path relative to the symlink's directory
+)
+
+// ParseLinkStyle validates a link style name
+func ParseLinkStyle(s string) (LinkStyle, error) {
+	switch LinkStyle(s) {
+	case LinkAbsolute, LinkRelative:
+		return LinkStyle(s), nil
+	}
+	return "", fmt.Errorf("invalid link style %q (must be absolute or relative)", s)
+}
+
+// GetLinkStyle returns the repo link style, absolute unless set
+func (m *Manifest) GetLinkStyle() LinkStyle {
+	if m.LinkStyle != "" {
+		return m.LinkStyle
+	}
+	return LinkAbsolute
+}
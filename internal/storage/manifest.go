@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestFilename is the name of the metadata file kept in each repo storage directory
+const ManifestFilename = ".manifest.json"
+
+// Manifest holds metadata about the files stored for a repository
+type Manifest struct {
+	LinkMode  LinkMode              `json:"link_mode,omitempty"`  // Repo default, symlink when empty
+	LinkStyle LinkStyle             `json:"link_style,omitempty"` // How symlinks refer to storage, absolute when empty
+	Files     map[string]*FileEntry `json:"files"`                // Keyed by storage filename
+	Pending   []string              `json:"pending,omitempty"`    // Repo paths to link once their directory exists
+	Clones    []string              `json:"clones,omitempty"`     // Root of every working tree that used this storage
+	Patterns  []string              `json:"patterns,omitempty"`   // File name globs to manage, CLAUDE.md when empty
+	Tracked   TrackedPolicy         `json:"tracked,omitempty"`    // What to do with files git tracks, refuse when empty
+	Overlays  map[string]string     `json:"overlays,omitempty"`   // Personal companion of each tracked file, by its repo path
+	// ExcludeFile lists linked paths for git to ignore, relative to the repo root; .git/info/exclude when empty
+	ExcludeFile string `json:"exclude_file,omitempty"`
+}
+
+// FileEntry records what claude-md last knew about a stored file
+type FileEntry struct {
+	Hash      string    `json:"hash"`                // sha256 of the content at the last save or sync
+	UpdatedAt time.Time `json:"updated_at"`          // When the content was last written to storage
+	LinkMode  LinkMode  `json:"link_mode,omitempty"` // Overrides the repo default for this file
+	// Copies maps the absolute path of each hardlink or copy to the hash both sides had at the last sync
+	Copies map[string]string `json:"copies,omitempty"`
+	// Ejected lists the absolute path of each working tree file ejected from management
+	Ejected []string `json:"ejected,omitempty"`
+}
+
+// LoadManifest reads the manifest for this repo, returning an empty manifest if none exists
+func (pc *PathConverter) LoadManifest() (*Manifest, error) {
+	m := &Manifest{Files: make(map[string]*FileEntry)}
+
+	data, err := os.ReadFile(pc.GetManifestPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", pc.GetManifestPath(), err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]*FileEntry)
+	}
+	return m, nil
+}
+
+// SaveManifest writes the manifest for this repo
+func (pc *PathConverter) SaveManifest(m *Manifest) error {
+	if err := pc.EnsureStorageDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	// A crash never leaves a truncated manifest
+	if err := WriteAtomic(pc.GetManifestPath(), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// GetManifestPath returns the path to this repo's manifest file
+func (pc *PathConverter) GetManifestPath() string {
+	return filepath.Join(pc.GetRepoStorageDir(), ManifestFilename)
+}
+
+// Record notes that content was written to storage under storageName
+func (m *Manifest) Record(storageName string, content []byte) {
+	entry, ok := m.Files[storageName]
+	if !ok {
+		entry = &FileEntry{}
+		m.Files[storageName] = entry
+	}
+	entry.Hash = HashContent(content)
+	entry.UpdatedAt = time.Now().UTC()
+}
+
+// SetCopyHash records the content hash a placed copy and storage agreed on
+func (m *Manifest) SetCopyHash(storageName, path, hash string) {
+	entry, ok := m.Files[storageName]
+	if !ok {
+		entry = &FileEntry{}
+		m.Files[storageName] = entry
+	}
+	if entry.Copies == nil {
+		entry.Copies = make(map[string]string)
+	}
+	entry.Copies[path] = hash
+}
+
+// CopyHash returns the hash recorded for a placed copy, or "" if it was never synced
+func (m *Manifest) CopyHash(storageName, path string) string {
+	if entry, ok := m.Files[storageName]; ok {
+		return entry.Copies[path]
+	}
+	return ""
+}
+
+// RemoveCopy forgets a placed copy
+func (m *Manifest) RemoveCopy(storageName, path string) {
+	if entry, ok := m.Files[storageName]; ok {
+		delete(entry.Copies, path)
+	}
+}
+
+// SetEjected records whether the working tree file at path was ejected, so
+// claude-md leaves it alone until it is saved or restored again
+func (m *Manifest) SetEjected(storageName, path string, ejected bool) {
+	entry, ok := m.Files[storageName]
+	if !ok {
+		if !ejected {
+			return
+		}
+		entry = &FileEntry{}
+		m.Files[storageName] = entry
+	}
+	for i, p := range entry.Ejected {
+		if p == path {
+			if !ejected {
+				entry.Ejected = append(entry.Ejected[:i], entry.Ejected[i+1:]...)
+			}
+			return
+		}
+	}
+	if ejected {
+		entry.Ejected = append(entry.Ejected, path)
+	}
+}
+
+// IsEjected reports whether the working tree file at path was ejected
+func (m *Manifest) IsEjected(storageName, path string) bool {
+	if entry, ok := m.Files[storageName]; ok {
+		for _, p := range entry.Ejected {
+			if p == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Remove forgets everything recorded about storageName
+func (m *Manifest) Remove(storageName string) {
+	delete(m.Files, storageName)
+}
+
+// Rename moves the entry for oldName to newName
+func (m *Manifest) Rename(oldName, newName string) {
+	if entry, ok := m.Files[oldName]; ok {
+		delete(m.Files, oldName)
+		m.Files[newName] = entry
+	}
+}
+
+// AddPending defers restoring repoRelativePath until its parent directory exists
+func (m *Manifest) AddPending(repoRelativePath string) {
+	for _, p := range m.Pending {
+		if p == repoRelativePath {
+			return
+		}
+	}
+	m.Pending = append(m.Pending, repoRelativePath)
+}
+
+// RemovePending drops repoRelativePath from the pending list
+func (m *Manifest) RemovePending(repoRelativePath string) {
+	for i, p := range m.Pending {
+		if p == repoRelativePath {
+			m.Pending = append(m.Pending[:i], m.Pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetOverlay records the personal companion managed for a tracked file
+func (m *Manifest) SetOverlay(trackedPath, companionPath string) {
+	if m.Overlays == nil {
+		m.Overlays = make(map[string]string)
+	}
+	m.Overlays[trackedPath] = companionPath
+}
+
+// AddClone remembers a working tree root, returning false if it was already known
+func (m *Manifest) AddClone(root string) bool {
+	for _, c := range m.Clones {
+		if c == root {
+			return false
+		}
+	}
+	m.Clones = append(m.Clones, root)
+	return true
+}
+
+// HashContent returns the hex encoded sha256 of content
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,63 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	pc := &storage.PathConverter{
+		StorageRoot: filepath.Join(t.TempDir(), "storage"),
+		RepoName:    "test.git",
+	}
+
+	m, err := pc.LoadManifest()
+	require.NoError(t, err)
+	assert.Empty(t, m.Files)
+
+	m.Record("docs~CLAUDE.md", []byte("content"))
+	require.NoError(t, pc.SaveManifest(m))
+
+	loaded, err := pc.LoadManifest()
+	require.NoError(t, err)
+	require.Contains(t, loaded.Files, "docs~CLAUDE.md")
+	assert.Equal(t, storage.HashContent([]byte("content")), loaded.Files["docs~CLAUDE.md"].Hash)
+}
+
+func TestLoadManifestCorrupt(t *testing.T) {
+	pc := &storage.PathConverter{
+		StorageRoot: filepath.Join(t.TempDir(), "storage"),
+		RepoName:    "test.git",
+	}
+	require.NoError(t, pc.EnsureStorageDir())
+	require.NoError(t, os.WriteFile(pc.GetManifestPath(), []byte("{not json"), 0600))
+
+	_, err := pc.LoadManifest()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse manifest")
+}
+
+func TestRecordVersion(t *testing.T) {
+	pc := &storage.PathConverter{
+		StorageRoot: filepath.Join(t.TempDir(), "storage"),
+		RepoName:    "test.git",
+	}
+
+	hash, err := pc.RecordVersion("CLAUDE.md", []byte("v1"))
+	require.NoError(t, err)
+	assert.Equal(t, storage.HashContent([]byte("v1")), hash)
+
+	// Recording the same content again is a no-op
+	again, err := pc.RecordVersion("CLAUDE.md", []byte("v1"))
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	content, err := pc.LoadVersion("CLAUDE.md", hash)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+}
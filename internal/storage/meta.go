@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"os"
+	"time"
+)
+
+// FileMeta is the metadata carried along when content moves between the
+// working tree and storage
+type FileMeta struct {
+	Mode    os.FileMode       // Permission bits
+	ModTime time.Time         // Last modification time
+	Xattrs  map[string][]byte // Extended attributes, where the platform supports them
+}
+
+// ReadMeta returns the metadata of the file at path
+// Extended attributes that cannot be read are left out
+func ReadMeta(path string) (FileMeta, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileMeta{}, err
+	}
+	return FileMeta{
+		Mode:    info.Mode().Perm(),
+		ModTime: info.ModTime(),
+		Xattrs:  readXattrs(path),
+	}, nil
+}
+
+// ApplyMeta gives the file at path the mode, modification time and extended
+// attributes in meta. Extended attributes are best effort, since the target
+// filesystem may not support them or may reserve some namespaces.
+func ApplyMeta(path string, meta FileMeta) error {
+	if err := os.Chmod(path, meta.Mode); err != nil {
+		return err
+	}
+	writeXattrs(path, meta.Xattrs)
+	if meta.ModTime.IsZero() {
+		return nil
+	}
+	return os.Chtimes(path, meta.ModTime, meta.ModTime)
+}
@@ -0,0 +1,35 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyMetaXattrs(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	target := filepath.Join(dir, "target")
+	require.NoError(t, os.WriteFile(source, []byte("content"), 0644))
+	require.NoError(t, os.WriteFile(target, []byte("content"), 0644))
+
+	if err := syscall.Setxattr(source, "user.claude-md.test", []byte("value"), 0); err != nil {
+		t.Skipf("filesystem does not support user xattrs: %v", err)
+	}
+
+	meta, err := storage.ReadMeta(source)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), meta.Xattrs["user.claude-md.test"])
+
+	require.NoError(t, storage.ApplyMeta(target, meta))
+
+	value := make([]byte, 16)
+	n, err := syscall.Getxattr(target, "user.claude-md.test", value)
+	require.NoError(t, err)
+	assert.Equal(t, "value", string(value[:n]))
+}
@@ -0,0 +1,34 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kapetan-io/claude-md.go/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyMeta(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	target := filepath.Join(dir, "target")
+	require.NoError(t, os.WriteFile(source, []byte("content"), 0600))
+	require.NoError(t, os.WriteFile(target, []byte("content"), 0644))
+
+	mtime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(source, mtime, mtime))
+
+	meta, err := storage.ReadMeta(source)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), meta.Mode)
+
+	require.NoError(t, storage.ApplyMeta(target, meta))
+
+	info, err := os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.True(t, info.ModTime().Equal(mtime))
+}
@@ -10,22 +10,32 @@ import (
 
 // PathConverter handles conversion between repository paths and storage paths
 type PathConverter struct {
-	StorageRoot string // ~/.claude/claude-md/<user>
-	RepoName    string // Repository name as extracted from origin URL (e.g., "kapetan.git" or "kapetan")
+	StorageRoot string   // ~/.claude/claude-md/<user>
+	RepoName    string   // Repository name as extracted from origin URL (e.g., "kapetan.git" or "kapetan")
+	Patterns    []string // File name globs managed for this repo, CLAUDE.md when empty
+}
+
+// BaseDir returns the directory holding every user's storage, which does not
+// depend on the repository
+// Returns: ~/.claude/claude-md/
+func BaseDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude", "claude-md"), nil
 }
 
 // NewPathConverter creates a new path converter
 // Returns error if home directory cannot be determined
 func NewPathConverter(user, repoName string) (*PathConverter, error) {
-	home, err := os.UserHomeDir()
+	baseDir, err := BaseDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
 
-	storageRoot := filepath.Join(home, ".claude", "claude-md", user)
-
 	return &PathConverter{
-		StorageRoot: storageRoot,
+		StorageRoot: filepath.Join(baseDir, user),
 		RepoName:    repoName,
 	}, nil
 }
@@ -93,6 +103,12 @@ func (pc *PathConverter) GetRepoStorageDir() string {
 	return filepath.Join(pc.StorageRoot, pc.RepoName)
 }
 
+// GetStorageBaseDir returns the directory holding every user's namespace
+// Returns: ~/.claude/claude-md/
+func (pc *PathConverter) GetStorageBaseDir() string {
+	return filepath.Dir(pc.StorageRoot)
+}
+
 // ValidatePath checks if path contains invalid characters (like ~)
 func ValidatePath(path string) error {
 	if strings.Contains(path, "~") {
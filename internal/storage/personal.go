@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const personalDirName = ".personal"
+
+// LoadPersonalBlock returns the personal block kept for the committed file
+// storageName, or nil if it has none
+func (pc *PathConverter) LoadPersonalBlock(storageName string) ([]byte, error) {
+	content, err := os.ReadFile(filepath.Join(pc.GetRepoStorageDir(), personalDirName, storageName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// SavePersonalBlock keeps block as the personal block of the committed file
+// storageName. A blank block removes it. Returns whether anything changed.
+func (pc *PathConverter) SavePersonalBlock(storageName string, block []byte) (bool, error) {
+	path := filepath.Join(pc.GetRepoStorageDir(), personalDirName, storageName)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read personal block: %w", err)
+	}
+
+	if len(bytes.TrimSpace(block)) == 0 {
+		if err != nil {
+			return false, nil
+		}
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("failed to remove personal block: %w", err)
+		}
+		return true, nil
+	}
+
+	if err == nil && string(existing) == string(block) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return false, fmt.Errorf("failed to create personal block directory: %w", err)
+	}
+	if err := WriteAtomic(path, block, 0600); err != nil {
+		return false, fmt.Errorf("failed to write personal block: %w", err)
+	}
+	return true, nil
+}
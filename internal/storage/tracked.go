@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// TrackedPolicy is what claude-md does with managed files that git tracks
+type TrackedPolicy string
+
+const (
+	TrackedRefuse       TrackedPolicy = "refuse"        // Leave them alone (default)
+	TrackedSkipWorktree TrackedPolicy = "skip-worktree" // Link them and mark them skip-worktree so git ignores the link
+	TrackedLocal        TrackedPolicy = "local"         // Leave them alone and manage a personal <name>.local<ext> next to each
+)
+
+// TrackedPolicies lists the supported policies for tracked files
+var TrackedPolicies = []TrackedPolicy{TrackedRefuse, TrackedSkipWorktree, TrackedLocal}
+
+// ParseTrackedPolicy validates a tracked file policy name
+func ParseTrackedPolicy(s string) (TrackedPolicy, error) {
+	for _, p := range TrackedPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("invalid tracked file policy %q (must be one of: refuse, skip-worktree, local)", s)
+}
+
+// GetTrackedPolicy returns the repo policy for tracked files, refuse unless set
+func (m *Manifest) GetTrackedPolicy() TrackedPolicy {
+	if m.Tracked != "" {
+		return m.Tracked
+	}
+	return TrackedRefuse
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+package storage
+
+import (
+	"bytes"
+	"syscall"
+)
+
+// readXattrs returns the extended attributes of path, or nil if it has none
+func readXattrs(path string) map[string][]byte {
+	size, err := syscall.Listxattr(path, nil)
+	if err != nil || size <= 0 {
+		return nil
+	}
+	buf := make([]byte, size)
+	size, err = syscall.Listxattr(path, buf)
+	if err != nil {
+		return nil
+	}
+
+	attrs := make(map[string][]byte)
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		n, err := syscall.Getxattr(path, string(name), nil)
+		if err != nil {
+			continue
+		}
+		value := make([]byte, n)
+		if n, err = syscall.Getxattr(path, string(name), value); err != nil {
+			continue
+		}
+		attrs[string(name)] = value[:n]
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// writeXattrs sets the extended attributes on path, skipping any the filesystem refuses
+func writeXattrs(path string, attrs map[string][]byte) {
+	for name, value := range attrs {
+		_ = syscall.Setxattr(path, name, value, 0)
+	}
+}
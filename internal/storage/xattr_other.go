@@ -0,0 +1,11 @@
+//go:build !linux
+
+package storage
+
+// readXattrs returns nil where extended attributes are not supported
+func readXattrs(path string) map[string][]byte {
+	return nil
+}
+
+// writeXattrs does nothing where extended attributes are not supported
+func writeXattrs(path string, attrs map[string][]byte) {}
@@ -0,0 +1,207 @@
+// Package watch reports changes to files in a working tree, using inotify
+// where available and falling back to polling elsewhere.
+package watch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultInterval is how often the polling watcher scans the tree
+const DefaultInterval = 2 * time.Second
+
+// Options configures a Watcher
+type Options struct {
+	Root     string
+	Match    func(name string) bool // File names worth reporting, nil reports every file
+	Interval time.Duration          // Scan interval when polling, DefaultInterval when zero
+	Poll     bool                   // Poll even where native notifications are available
+}
+
+// Watcher reports paths below the root that were created, changed or removed
+type Watcher interface {
+	// Events delivers changed paths until the watcher is closed
+	Events() <-chan string
+	// Close stops the watcher and closes the events channel
+	Close() error
+}
+
+// New returns a native watcher for opts.Root, or a polling watcher if native
+// notifications are unavailable or run out of watches
+func New(opts Options) (Watcher, error) {
+	if opts.Match == nil {
+		opts.Match = func(string) bool { return true }
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if !opts.Poll {
+		if w, err := newNative(opts); err == nil {
+			return w, nil
+		}
+	}
+	return newPoller(opts)
+}
+
+// Native reports whether w uses native notifications rather than polling
+func Native(w Watcher) bool {
+	_, polling := w.(*poller)
+	return !polling
+}
+
+// Debounce groups events that arrive within quiet of each other and delivers
+// each group once the tree has been quiet for that long. The returned channel
+// is closed when events is.
+func Debounce(events <-chan string, quiet time.Duration) <-chan []string {
+	out := make(chan []string)
+	go func() {
+		defer close(out)
+
+		pending := make(map[string]bool)
+		timer := time.NewTimer(quiet)
+		timer.Stop()
+
+		flush := func() {
+			batch := make([]string, 0, len(pending))
+			for path := range pending {
+				batch = append(batch, path)
+			}
+			pending = make(map[string]bool)
+			out <- batch
+		}
+
+		for {
+			select {
+			case path, ok := <-events:
+				if !ok {
+					if len(pending) > 0 {
+						flush()
+					}
+					return
+				}
+				pending[path] = true
+				timer.Reset(quiet)
+			case <-timer.C:
+				if len(pending) > 0 {
+					flush()
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// skipDir reports whether a directory is never watched
+func skipDir(name string) bool {
+	return name == ".git"
+}
+
+// poller finds changes by comparing periodic scans of matching files
+type poller struct {
+	opts   Options
+	events chan string
+	done   chan struct{}
+}
+
+type fileState struct {
+	mode    fs.FileMode
+	size    int64
+	modTime time.Time
+	target  string // Link target, so re-pointed symlinks count as changes
+}
+
+func newPoller(opts Options) (*poller, error) {
+	if _, err := os.Stat(opts.Root); err != nil {
+		return nil, err
+	}
+	p := &poller{opts: opts, events: make(chan string), done: make(chan struct{})}
+	// The first scan is the baseline, later changes are reported against it
+	go p.run(p.scan())
+	return p, nil
+}
+
+func (p *poller) Events() <-chan string {
+	return p.events
+}
+
+func (p *poller) Close() error {
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
+	return nil
+}
+
+func (p *poller) run(last map[string]fileState) {
+	defer close(p.events)
+
+	ticker := time.NewTicker(p.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := p.scan()
+		for path, state := range current {
+			if prev, ok := last[path]; !ok || prev != state {
+				if !p.send(path) {
+					return
+				}
+			}
+		}
+		for path := range last {
+			if _, ok := current[path]; !ok {
+				if !p.send(path) {
+					return
+				}
+			}
+		}
+		last = current
+	}
+}
+
+func (p *poller) send(path string) bool {
+	select {
+	case p.events <- path:
+		return true
+	case <-p.done:
+		return false
+	}
+}
+
+// scan records the state of every matching file, statting only those
+func (p *poller) scan() map[string]fileState {
+	states := make(map[string]fileState)
+	_ = filepath.WalkDir(p.opts.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !p.opts.Match(d.Name()) {
+			return nil
+		}
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil
+		}
+		state := fileState{mode: info.Mode(), size: info.Size(), modTime: info.ModTime()}
+		if info.Mode()&os.ModeSymlink != 0 {
+			state.target, _ = os.Readlink(path)
+		}
+		states[path] = state
+		return nil
+	})
+	return states
+}
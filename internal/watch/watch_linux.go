@@ -0,0 +1,148 @@
+//go:build linux
+
+package watch
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_CLOSE_WRITE | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// inotify watches every directory of the tree and reports events on matching
+// files and on directories, whose appearance may bring matching files along
+type inotify struct {
+	opts   Options
+	file   *os.File
+	events chan string
+
+	mu    sync.Mutex
+	dirs  map[int32]string // Watch descriptor to directory
+	close sync.Once
+}
+
+func newNative(opts Options) (Watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	w := &inotify{
+		opts:   opts,
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		events: make(chan string),
+		dirs:   make(map[int32]string),
+	}
+	if _, err := w.addTree(opts.Root); err != nil {
+		_ = w.file.Close()
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *inotify) Events() <-chan string {
+	return w.events
+}
+
+func (w *inotify) Close() error {
+	var err error
+	w.close.Do(func() { err = w.file.Close() })
+	return err
+}
+
+// addTree watches dir and every directory below it, returning the matching
+// files already there
+func (w *inotify) addTree(dir string) ([]string, error) {
+	var found []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Directories can vanish while a checkout runs
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			if w.opts.Match(d.Name()) {
+				found = append(found, path)
+			}
+			return nil
+		}
+		if skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		wd, err := syscall.InotifyAddWatch(int(w.file.Fd()), path, inotifyMask)
+		if err != nil {
+			// Out of watches (ENOSPC) means this tree is too big for inotify
+			if errors.Is(err, syscall.ENOSPC) {
+				return err
+			}
+			return nil
+		}
+		w.mu.Lock()
+		w.dirs[int32(wd)] = path
+		w.mu.Unlock()
+		return nil
+	})
+	return found, err
+}
+
+func (w *inotify) run() {
+	defer close(w.events)
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(event.Len)]
+			offset += syscall.SizeofInotifyEvent + int(event.Len)
+
+			// Dropped events could have been anything, so report the whole tree
+			if event.Mask&syscall.IN_Q_OVERFLOW != 0 {
+				w.events <- w.opts.Root
+				continue
+			}
+
+			w.mu.Lock()
+			dir, ok := w.dirs[event.Wd]
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				delete(w.dirs, event.Wd)
+			}
+			w.mu.Unlock()
+			if !ok {
+				continue
+			}
+
+			name := string(bytes.TrimRight(nameBytes, "\x00"))
+			path := filepath.Join(dir, name)
+			isDir := event.Mask&syscall.IN_ISDIR != 0
+
+			var found []string
+			if isDir && event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 && !skipDir(name) {
+				// Files can land in a new directory before its watch is added
+				found, _ = w.addTree(path)
+			}
+			if name == "" {
+				path = dir
+			}
+			if name == "" || isDir || w.opts.Match(name) {
+				w.events <- path
+			}
+			for _, file := range found {
+				w.events <- file
+			}
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package watch
+
+import (
+	"errors"
+)
+
+// newNative is unavailable here, so New always polls
+func newNative(opts Options) (Watcher, error) {
+	return nil, errors.New("native file notifications are not supported on this platform")
+}
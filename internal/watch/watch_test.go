@@ -0,0 +1,95 @@
+package watch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kapetan-io/claude-md.go/internal/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitFor reads events until one for path arrives
+func waitFor(t *testing.T, w watch.Watcher, path string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got, ok := <-w.Events():
+			require.True(t, ok, "events closed before %s was reported", path)
+			if got == path {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no event for %s", path)
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		poll bool
+	}{
+		{name: "Native"},
+		{name: "Poll", poll: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			w, err := watch.New(watch.Options{
+				Root:     root,
+				Match:    func(name string) bool { return name == "CLAUDE.md" },
+				Interval: 20 * time.Millisecond,
+				Poll:     test.poll,
+			})
+			require.NoError(t, err)
+			defer func() { _ = w.Close() }()
+			if test.poll {
+				assert.False(t, watch.Native(w))
+			}
+
+			path := filepath.Join(root, "CLAUDE.md")
+			require.NoError(t, os.WriteFile(path, []byte("content"), 0644))
+			waitFor(t, w, path)
+
+			require.NoError(t, os.Remove(path))
+			waitFor(t, w, path)
+
+			// Files in directories created after the watcher started are seen too
+			sub := filepath.Join(root, "sub")
+			require.NoError(t, os.Mkdir(sub, 0755))
+			time.Sleep(50 * time.Millisecond)
+			subPath := filepath.Join(sub, "CLAUDE.md")
+			require.NoError(t, os.Symlink("/nonexistent", subPath))
+			waitFor(t, w, subPath)
+
+			require.NoError(t, w.Close())
+			for range w.Events() {
+			}
+		})
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	events := make(chan string)
+	batches := watch.Debounce(events, 50*time.Millisecond)
+
+	events <- "a"
+	events <- "b"
+	events <- "a"
+
+	select {
+	case batch := <-batches:
+		assert.ElementsMatch(t, []string{"a", "b"}, batch)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no batch delivered")
+	}
+
+	events <- "c"
+	close(events)
+	assert.Equal(t, []string{"c"}, <-batches)
+	_, ok := <-batches
+	assert.False(t, ok)
+}
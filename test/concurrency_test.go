@@ -0,0 +1,113 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/kapetan-io/claude-md.go/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProcess runs the CLI when the test binary is started by runClaudeMd
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("CLAUDE_MD_HELPER_PROCESS") != "1" {
+		t.Skip("only runs as a helper process")
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	os.Exit(cli.Run(args, cli.RunOptions{}))
+}
+
+// runClaudeMd starts claude-md in dir as a separate process
+func runClaudeMd(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=TestHelperProcess", "--"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CLAUDE_MD_HELPER_PROCESS=1")
+	return cmd
+}
+
+// claudeMdOnPath puts a claude-md that starts the test binary first on PATH, for
+// commands git runs itself such as hooks and filters
+func claudeMdOnPath(t *testing.T) {
+	testBinary, err := filepath.Abs(os.Args[0])
+	require.NoError(t, err)
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "claude-md"), []byte(fmt.Sprintf(
+		"#!/bin/sh\nCLAUDE_MD_HELPER_PROCESS=1 exec %q -test.run=TestHelperProcess -- \"$@\"\n", testBinary)), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestConcurrentSavesKeepAllContent(t *testing.T) {
+	const clones = 8
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+
+	// Clones of the same repository share storage, each saves its own file
+	var dirs []string
+	for i := 0; i < clones; i++ {
+		repoDir := filepath.Join(t.TempDir(), "repo")
+		require.NoError(t, os.MkdirAll(repoDir, 0755))
+		for _, args := range [][]string{
+			{"init"},
+			{"config", "user.email", "test@example.com"},
+			{"remote", "add", "origin", "https://github.com/test/repo.git"},
+		} {
+			gitCmd := exec.Command("git", args...)
+			gitCmd.Dir = repoDir
+			require.NoError(t, gitCmd.Run())
+		}
+
+		dir := filepath.Join(repoDir, fmt.Sprintf("pkg%d", i))
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte(fmt.Sprintf("clone %d", i)), 0644))
+		dirs = append(dirs, repoDir)
+	}
+
+	var wg sync.WaitGroup
+	outputs := make([][]byte, clones)
+	errs := make([]error, clones)
+	for i, dir := range dirs {
+		wg.Add(1)
+		go func(i int, dir string) {
+			defer wg.Done()
+			outputs[i], errs[i] = runClaudeMd(dir, "save").CombinedOutput()
+		}(i, dir)
+	}
+	wg.Wait()
+
+	for i := range dirs {
+		require.NoError(t, errs[i], string(outputs[i]))
+	}
+
+	data, err := os.ReadFile(filepath.Join(storageDir, ".manifest.json"))
+	require.NoError(t, err)
+	var manifest struct {
+		Files  map[string]json.RawMessage `json:"files"`
+		Clones []string                   `json:"clones"`
+	}
+	require.NoError(t, json.Unmarshal(data, &manifest))
+
+	assert.Len(t, manifest.Files, clones)
+	assert.Len(t, manifest.Clones, clones)
+	for i := 0; i < clones; i++ {
+		content, err := os.ReadFile(filepath.Join(storageDir, fmt.Sprintf("pkg%d~CLAUDE.md", i)))
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("clone %d", i), string(content))
+	}
+
+	_ = os.RemoveAll(storageDir)
+}
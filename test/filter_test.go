@@ -0,0 +1,123 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterKeepsPersonalBlockOutOfCommits(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+	t.Cleanup(func() { _ = os.RemoveAll(storageDir) })
+
+	claudeMdOnPath(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+		return string(output)
+	}
+	git("init")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	git("remote", "add", "origin", "https://github.com/test/repo.git")
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("team\n"), 0644))
+	git("add", "CLAUDE.md")
+	git("commit", "-m", "Add CLAUDE.md")
+
+	output, err := runClaudeMd(repoDir, "filter", "install").CombinedOutput()
+	require.NoError(t, err, string(output))
+	assert.Contains(t, string(output), "Filtered: CLAUDE.md")
+	assert.Equal(t, "true\n", git("config", "--local", "filter.claude-md.required"))
+
+	// Committing strips the block and keeps it in storage
+	personal := "team\nmore\n\n<!-- >>> claude-md personal >>> -->\nmine\n<!-- <<< claude-md personal <<< -->\n"
+	require.NoError(t, os.WriteFile(claudeFile, []byte(personal), 0644))
+	git("commit", "-am", "Commit with a personal block")
+	assert.Equal(t, "team\nmore\n", git("show", "HEAD:CLAUDE.md"))
+	assert.Empty(t, git("status", "--porcelain"))
+	stored, err := os.ReadFile(filepath.Join(storageDir, ".personal", "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "mine\n", string(stored))
+
+	// Checking out adds it back
+	require.NoError(t, os.Remove(claudeFile))
+	git("checkout", "--", "CLAUDE.md")
+	content, err := os.ReadFile(claudeFile)
+	require.NoError(t, err)
+	assert.Equal(t, personal, string(content))
+
+	output, err = runClaudeMd(repoDir, "filter", "uninstall").CombinedOutput()
+	require.NoError(t, err, string(output))
+	assert.Contains(t, string(output), "Unfiltered: CLAUDE.md (personal block kept in storage)")
+	content, err = os.ReadFile(claudeFile)
+	require.NoError(t, err)
+	assert.Equal(t, "team\nmore\n", string(content))
+	assert.Empty(t, git("status", "--porcelain"))
+	assert.NotContains(t, git("config", "--local", "--list"), "filter.claude-md")
+
+	attributes, err := os.ReadFile(filepath.Join(repoDir, ".git", "info", "attributes"))
+	require.NoError(t, err)
+	assert.False(t, strings.Contains(string(attributes), "claude-md"))
+}
+
+func TestFilterForgetsDeletedPersonalBlock(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+	t.Cleanup(func() { _ = os.RemoveAll(storageDir) })
+
+	claudeMdOnPath(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+		return string(output)
+	}
+	git("init")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	git("remote", "add", "origin", "https://github.com/test/repo.git")
+
+	claudeFile := filepath.Join(repoDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(claudeFile, []byte("team\n"), 0644))
+	git("add", "CLAUDE.md")
+	git("commit", "-m", "Add CLAUDE.md")
+	output, err := runClaudeMd(repoDir, "filter", "install").CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	personal := "team\n\n<!-- >>> claude-md personal >>> -->\nmine\n<!-- <<< claude-md personal <<< -->\n"
+	require.NoError(t, os.WriteFile(claudeFile, []byte(personal), 0644))
+	git("add", "CLAUDE.md")
+	require.FileExists(t, filepath.Join(storageDir, ".personal", "CLAUDE.md"))
+
+	// Deleting the markers deletes the block
+	require.NoError(t, os.WriteFile(claudeFile, []byte("team\n"), 0644))
+	git("add", "CLAUDE.md")
+	assert.NoFileExists(t, filepath.Join(storageDir, ".personal", "CLAUDE.md"))
+
+	require.NoError(t, os.Remove(claudeFile))
+	git("checkout", "--", "CLAUDE.md")
+	content, err := os.ReadFile(claudeFile)
+	require.NoError(t, err)
+	assert.Equal(t, "team\n", string(content))
+}
@@ -0,0 +1,39 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooksLeaveUnmappedRepositoriesAlone(t *testing.T) {
+	claudeMdOnPath(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+		return string(output)
+	}
+	// No origin remote, so there is no storage to map the repository to
+	git("init")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	output, err := runClaudeMd(repoDir, "hooks", "install").CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "CLAUDE.md"), []byte("team"), 0644))
+	git("add", "CLAUDE.md")
+	committed := git("commit", "-m", "Add CLAUDE.md")
+	assert.NotContains(t, committed, "Error")
+
+	// The post-checkout hook restores quietly
+	assert.NotContains(t, git("checkout", "-b", "feature"), "Error")
+}
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooksRestoreIntoNewWorktrees(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	storageDir := filepath.Join(home, ".claude", "claude-md", "test", "repo.git")
+	_ = os.RemoveAll(storageDir)
+	t.Cleanup(func() { _ = os.RemoveAll(storageDir) })
+	claudeMdOnPath(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	git("init")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	git("remote", "add", "origin", "https://github.com/test/repo.git")
+	git("commit", "--allow-empty", "-m", "Initial commit")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "CLAUDE.md"), []byte("personal"), 0644))
+	for _, args := range [][]string{{"save"}, {"hooks", "install"}} {
+		output, err := runClaudeMd(repoDir, args...).CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	worktreeDir := filepath.Join(t.TempDir(), "feature")
+	git("worktree", "add", "-b", "feature", worktreeDir)
+
+	target, err := os.Readlink(filepath.Join(worktreeDir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(storageDir, "CLAUDE.md"), target)
+}